@@ -1,6 +1,6 @@
 module github.com/StevenACoffman/khanerr/_example
 
-go 1.20
+go 1.21
 
 replace github.com/StevenACoffman/khanerr => ../
 
@@ -0,0 +1,37 @@
+package errors
+
+import "context"
+
+// fieldsKey is the context key for the fields added with WithFields.
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx that carries fields, along with any
+// fields already added to ctx, with the new ones winning. Passing the
+// context to an error constructor adds the fields to the error, so that
+// request-scoped metadata like the kaid or request ID is in every error
+// without repeating it:
+//
+//	ctx = errors.WithFields(ctx, errors.Fields{"kaid": kaid})
+//	...
+//	return errors.NotFound(ctx, "No such video")
+func WithFields(ctx context.Context, fields Fields) context.Context {
+	merged := FieldsFromContext(ctx)
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FieldsFromContext returns a copy of the fields added to ctx with
+// WithFields, which is empty if there are none.
+func FieldsFromContext(ctx context.Context) Fields {
+	fields := Fields{}
+	if ctx == nil {
+		return fields
+	}
+	existing, _ := ctx.Value(fieldsKey{}).(Fields)
+	for k, v := range existing {
+		fields[k] = v
+	}
+	return fields
+}
@@ -0,0 +1,72 @@
+package errors_test
+
+import (
+	"context"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+func (es *errorSuite) TestWithFields() {
+	ctx := errors.WithFields(context.Background(), errors.Fields{"kaid": "123", "tenant": "ka"})
+	ctx = errors.WithFields(ctx, errors.Fields{"requestID": "abc", "tenant": "district"})
+	es.Require().Equal(errors.Fields{
+		"kaid":      "123",
+		"requestID": "abc",
+		"tenant":    "district",
+	}, errors.FieldsFromContext(ctx))
+
+	// The fields are copied, so changing them doesn't change the context.
+	errors.FieldsFromContext(ctx)["kaid"] = "456"
+	es.Require().Equal("123", errors.FieldsFromContext(ctx)["kaid"])
+
+	es.Require().Empty(errors.FieldsFromContext(context.Background()))
+}
+
+func (es *errorSuite) TestContextFields() {
+	ctx := errors.WithFields(context.Background(), errors.Fields{"kaid": "123", "tenant": "ka"})
+	inner := errors.NotFound("No such video", errors.Fields{"kaid": "456", "video": "abc"})
+
+	e := errors.Internal(ctx, "Unable to load video", inner, errors.Fields{"tenant": "district"})
+	es.Require().Equal(errors.Fields{
+		"Kind":    "internal error",
+		"Message": "Unable to load video",
+		// The context's fields win over those of the wrapped error...
+		"kaid":  "123",
+		"video": "abc",
+		// ...but not over the Fields passed to the constructor.
+		"tenant": "district",
+	}, errors.GetFields(e))
+
+	// A context without fields adds nothing.
+	e = errors.NotFound(context.Background(), "No such video")
+	es.Require().Equal(errors.Fields{
+		"Kind":    "not found",
+		"Message": "No such video",
+	}, errors.GetFields(e))
+}
+
+func (es *errorSuite) TestMultipleContexts() {
+	first := errors.WithFields(context.Background(), errors.Fields{"kaid": "123"})
+	second := errors.WithFields(context.Background(), errors.Fields{"tenant": "ka"})
+
+	e := errors.Internal(first, second)
+	fields := errors.GetFields(e)
+	es.Require().Equal("ka", fields["tenant"])
+	es.Require().NotContains(fields, "kaid")
+	es.Require().Equal([]string{"context.Context"}, fields[errors.InvalidErrArgsKey])
+
+	// The contents of dropped contexts are never shown.
+	type ctxKey struct{}
+	leaky := context.WithValue(first, ctxKey{}, errors.Secret("hunter2"))
+	leaky = context.WithValue(leaky, ctxKey{}, "session-abc")
+	e = errors.Internal(leaky, second)
+	es.Require().NotContains(e.Error(), "hunter2")
+	es.Require().NotContains(e.Error(), "session-abc")
+	es.Require().NotContains(e.Error(), "kaid")
+
+	e = errors.Internal(errors.KeepAll, first, second)
+	fields = errors.GetFields(e)
+	es.Require().Equal("ka", fields["tenant"])
+	es.Require().Equal("123", fields["kaid"])
+	es.Require().NotContains(fields, errors.InvalidErrArgsKey)
+}
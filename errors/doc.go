@@ -22,6 +22,19 @@
 // errors as well as giving us information in the logs about what kind
 // of thing went wrong.
 //
+// --- Custom Error Kinds ---
+//
+// Applications can add their own kinds when none of the core kinds fit.
+// Registered kinds work with IsValidKind, GetKind, IsKhanError and Wrap
+// just like the core kinds, and errors are created with the kind's New method:
+//
+// 	var QuotaExceededKind = errors.NewKind("quota exceeded")
+//
+// 	err := QuotaExceededKind.New("Too many requests", errors.Fields{"kaid": kaid})
+//
+// Use RegisterKind instead of NewKind to get an error rather than a panic
+// for duplicate or conflicting registrations.
+//
 // --- New Error Creation ---
 //
 // There are functions for each error kind (e.g. `NotFoundKind`) to create errors, e.g. `NotFound`,
@@ -37,6 +50,11 @@
 // 2. a string to use as the error message
 // 3. an errors.Fields{} object of key/value pairs to associate with the error
 // 4. an errors.Source("source-location") to override the default source-loc
+// 5. errors.KeepAll, to keep every error, message and Fields given
+// 6. a context.Context, whose errors.WithFields fields are added to the error
+// 7. any number of typed fields, made with a FieldKey's Of method
+// 8. an errors.Severity, like errors.SeverityWarning, to override the kind's default
+// 9. an errors.Public("message") to show users instead of the kind's default
 //
 // You should always provide one of (1) and (2); you can provide both
 // if it's helpful.  (3) is used to detail things like the name of the
@@ -44,7 +62,36 @@
 // embed these details in the error message - by putting them in
 // fields you make it much easier to search logs for them.
 //
-// If you specify any one type multiple times, only the last one wins.
+// (4) is kept as the "Source" field, even when the error is wrapped, and
+// can be read back with GetSource(err). Without it, GetSource returns the
+// function that created the error.
+//
+// (6) saves repeating request-scoped fields, like the kaid or request ID,
+// in every error. Add them to the context once with errors.WithFields; they
+// win over the fields of the wrapped error, but not over (3):
+//
+// 	ctx = errors.WithFields(ctx, errors.Fields{"kaid": kaid})
+// 	return errors.NotFound(ctx, "No such video")
+//
+// (7) catches typos in keys, and values of the wrong type, at compile time.
+// Declare each key once, and use it both to set and to read the field:
+//
+// 	var KAID = errors.NewFieldKey[string]("kaid")
+//
+// 	err := errors.NotFound("No such user", KAID.Of(kaid))
+// 	kaid, ok := KAID.Get(err)
+//
+// Typed fields win over the Fields passed to the same constructor, and are
+// seen by GetFields like any other field.
+//
+// If you pass more than one error, message, Fields or context, only the
+// last one is used, and the others are reported as invalid arguments in the
+// InvalidErrArgsKey field. To keep them all instead, also pass
+// errors.KeepAll: the errors are joined, the Fields and the contexts' fields
+// are merged and the extra messages are kept in the ExtraMessagesKey field.
+// There is no way to keep more than one Source, Severity or Public, so extra
+// ones are reported even with KeepAll. Typed fields can be passed any number
+// of times.
 //
 // --- IS / AS / ETC ---
 //
@@ -66,15 +113,42 @@
 //
 // The `Unwrap` function works normally and returns wrapped errors.
 //
-// The `As` function works normally, but isn't very useful with Khan errors.
-// Since the `errorKind` type is private you can't use `As` to search for it.
-// Moreover since error kinds aren't types you can't use them with `As`.
-// However you can use `As` to find wrapped errors of other public error
-// types.
+// The `As` function works normally. The constructors return an `*errors.Error`,
+// so you can use `As` to get at a khan error, even one wrapped by another
+// package, and inspect it with its `Kind`, `Message`, `Fields`, `Cause` and
+// `StackTrace` methods:
+//
+// 	var khanErr *errors.Error
+// 	if errors.As(err, &khanErr) {
+// 	    kind, fields := khanErr.Kind(), khanErr.Fields()
+// 	}
+//
+// Since error kinds aren't types you can't use them with `As`; use `Is` or
+// `GetKind` instead. You can also use `As` to find wrapped errors of other
+// public error types.
+//
+// --- JOIN ---
+//
+// Join and Combine turn several errors, e.g. from a batch operation, into
+// one. The kind of the combined error is the most severe of their kinds
+// for Join, and is picked by a KindStrategy for Combine. Errors that
+// aren't khan errors count as InternalKind, like they do for Wrap. Is, As,
+// GetKind and GetFields all look through every one of the combined errors:
+//
+// 	err := errors.Combine(errors.MostCommonKind, errs...)
+//
+// --- RETRY ---
+//
+// IsRetryable(err) is true for the transient kinds, and for kinds
+// registered with RegisterRetryable. Retry calls a function until it
+// succeeds, with exponential backoff and jitter, but gives up at once on
+// errors that aren't retryable, or when the context is done:
+//
+// 	err := errors.Retry(ctx, errors.DefaultRetryPolicy, callService)
 //
 // --- WRAP ---
 //
-// This package also provides a utility to "wrap" an existing khanError
+// This package also provides a utility to "wrap" an existing khan error
 // to add more fields:
 //
 // 	errors.Wrap(err, "newfield", "newvalue", "did i just wrap?", true)
@@ -84,11 +158,79 @@
 // 	errors.<SameKindAsErr>("", err,
 // 	    errors.Fields{"newfield": "newvalue", "did i just wrap?": true})
 //
+// --- PRINTING ---
+//
+// Error() has every field of every error in the chain, for the logs.
+// Formatting with %v prints a concise one-liner of the kind and messages,
+// %+v prints the whole chain with "Wrapped by:" lines, fields and stack
+// frames, and %#v prints a Go-syntax dump.
+//
 // ### GetFields
 // When logging, it is recommended to use GetFields(err) to collect all the Fields
 // of nested errors but ensure that the last key value pair wins.
 //
 // For instance, if a `Field{"message":"oh no!"}` is set on an error that is wrapped inside a new
 // error that has `Field{"message":"nevermind"}`, then the value for `message` key is `nevermind`.
+//
+// --- FINGERPRINTS ---
+//
+// Fingerprint(err) is a stable hash for grouping errors, made from the
+// kinds, message templates and field keys in the chain, and the function
+// names of the innermost stack frames. FingerprintWithOptions can leave
+// out some frames or keys.
+//
+// --- REDACTION ---
+//
+// Field values wrapped with Secret, and those whose keys were registered
+// with RegisterSensitiveKeys or RegisterSensitivePattern, are shown as
+// RedactedValue (or as a keyed hash, after SetRedactionHashKey) by Error(),
+// GetFields, JSON, logging and the other integrations. GetRawFields returns
+// the real values.
+//
+// --- SEVERITY ---
+//
+// Every kind has a default Severity, from SeverityDebug to
+// SeverityCritical, which a single error can override by passing a
+// Severity to its constructor. GetSeverity(err) walks the chain with the
+// rule set by SetSeverityRule: OutermostSeverity (the default) or
+// MaxSeverity. NewSlogHandler uses it for the level of each record with
+// an error when SlogOptions.LevelFromSeverity is set.
+//
+// --- PUBLIC MESSAGES ---
+//
+// PublicMessage(err) is the message to show users: the outermost one given
+// with Public, or else the public message of the error's kind, like "Not
+// found". It never includes the error's own message or fields, and is what
+// NewProblem and WriteProblem use for the problem's detail.
+//
+// --- TESTING ---
+//
+// The errtest package has test assertions like
+// errtest.AssertKind(t, err, errors.NotFoundKind) and
+// errtest.AssertField(t, err, "kaid", "123"), whose failure messages show
+// the whole error chain.
+//
+// --- HTTP ---
+//
+// HTTPStatus(err) gives the HTTP status for the error's kind, e.g. 404 for
+// NotFoundKind. WriteProblem(w, err, "allowed", "fields") writes the error
+// as an RFC 9457 application/problem+json response, with the error's
+// PublicMessage and only the named fields, and ParseProblem turns such a
+// response body back into an error of the matching kind.
+//
+// --- PANICS ---
+//
+// Recover and RecoverValue turn a recovered panic into an Internal error,
+// with the panic's stack trace and fields describing the panic value.
+// RecoverHandler is net/http middleware that does the same for a handler:
+//
+// 	defer errors.Recover(&err)
+//
+// --- LOGGING ---
+//
+// Khan errors implement slog.LogValuer, so log/slog logs them as a group
+// with their kind, message, fields and stack trace. To do the same for
+// errors that merely wrap a khan error, wrap your handler with
+// NewSlogHandler.
 
 package errors
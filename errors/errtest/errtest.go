@@ -0,0 +1,208 @@
+// Package errtest has test assertions for khanerr errors, so that tests
+// don't have to repeat GetKind, GetFields and map comparisons:
+//
+//	errtest.AssertKind(t, err, errors.NotFoundKind)
+//	errtest.AssertField(t, err, "kaid", "123")
+//
+// The assertions take a testing.TB, report failures with t.Errorf and
+// return whether they passed, like testify's assert package, but without
+// depending on it. Each failure message ends with the error chain, one
+// line per error, showing the kind, message and fields of each.
+package errtest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+// AssertKind checks that the kind of err is kind, e.g. errors.NotFoundKind.
+func AssertKind(t testing.TB, err, kind error) bool {
+	t.Helper()
+	if err == nil {
+		t.Errorf("expected an error of kind %q, got nil", kind)
+		return false
+	}
+	if got := errors.GetKind(err); error(got) != kind {
+		t.Errorf("error kind: got %q, want %q\n%s", got, kind, Chain(err))
+		return false
+	}
+	return true
+}
+
+// AssertField checks that GetFields(err) has key, with a value deeply
+// equal to want.
+func AssertField(t testing.TB, err error, key string, want any) bool {
+	t.Helper()
+	if err == nil {
+		t.Errorf("expected an error with field %q, got nil", key)
+		return false
+	}
+	got, ok := errors.GetFields(err)[key]
+	if !ok {
+		t.Errorf("error field %q: missing, want %#v\n%s", key, want, Chain(err))
+		return false
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("error field %q: got %#v, want %#v\n%s", key, got, want, Chain(err))
+		return false
+	}
+	return true
+}
+
+// AssertFields checks that GetFields(err) is exactly want, other than
+// the Kind and Message fields, which are checked by AssertKind and
+// AssertMessage. Every field that differs is listed on failure.
+func AssertFields(t testing.TB, err error, want errors.Fields) bool {
+	t.Helper()
+	if err == nil {
+		t.Errorf("expected an error with fields, got nil")
+		return false
+	}
+	got := errors.GetFields(err)
+	delete(got, errors.KindKey)
+	delete(got, errors.MessageKey)
+	if diff := diffFields(got, want); diff != "" {
+		t.Errorf("error fields differ (-got +want):\n%s%s", diff, Chain(err))
+		return false
+	}
+	return true
+}
+
+// AssertMessage checks that the message of err is want.
+func AssertMessage(t testing.TB, err error, want string) bool {
+	t.Helper()
+	if err == nil {
+		t.Errorf("expected an error with message %q, got nil", want)
+		return false
+	}
+	if got := message(err); got != want {
+		t.Errorf("error message: got %q, want %q\n%s", got, want, Chain(err))
+		return false
+	}
+	return true
+}
+
+// AssertWraps checks that errors.Is(err, target), i.e. that err is
+// target or wraps it. target may also be a kind.
+func AssertWraps(t testing.TB, err, target error) bool {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Errorf("error does not wrap %q\n%s", target, Chain(err))
+		return false
+	}
+	return true
+}
+
+// AssertNoInvalidArgs checks that err wasn't given any invalid
+// constructor arguments, which newError reports in the
+// errors.InvalidErrArgsKey field rather than failing.
+func AssertNoInvalidArgs(t testing.TB, err error) bool {
+	t.Helper()
+	if invalid, ok := errors.GetFields(err)[errors.InvalidErrArgsKey]; ok {
+		t.Errorf("error has invalid constructor arguments: %s\n%s",
+			errors.StringifyField(invalid), Chain(err))
+		return false
+	}
+	return true
+}
+
+// Chain describes err and the errors it wraps, one per line, starting
+// with err. Each line has the kind, message and fields of the error:
+//
+//	error chain:
+//	  internal error: Unable to load {attempt: 2}
+//	  wraps not found: No such user {kaid: "123"}
+func Chain(err error) string {
+	if err == nil {
+		return "error chain: <nil>\n"
+	}
+	var b strings.Builder
+	b.WriteString("error chain:\n")
+	fields := errors.GetFields(err)
+	fields[errors.KindKey] = errors.GetKind(err).String()
+	fields[errors.MessageKey] = message(err)
+	writeLayer(&b, "  ", fields)
+	for _, wrapped := range errors.GetWrappedErrors(err) {
+		writeLayer(&b, "  wraps ", wrapped)
+	}
+	return b.String()
+}
+
+func writeLayer(b *strings.Builder, prefix string, fields errors.Fields) {
+	b.WriteString(prefix)
+	if kind, ok := fields[errors.KindKey]; ok {
+		_, _ = fmt.Fprintf(b, "%v: ", kind)
+	}
+	_, _ = fmt.Fprintf(b, "%v", fields[errors.MessageKey])
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if k != errors.KindKey && k != errors.MessageKey {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > 0 {
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = fmt.Sprintf("%s: %#v", k, fields[k])
+		}
+		_, _ = fmt.Fprintf(b, " {%s}", strings.Join(parts, ", "))
+	}
+	b.WriteString("\n")
+}
+
+// message returns the Message field of err, or its Error() if it has none.
+func message(err error) string {
+	if message, ok := errors.GetFields(err)[errors.MessageKey].(string); ok {
+		return message
+	}
+	return err.Error()
+}
+
+// diffFields lists the fields that are only in got with a "-", those that
+// are only in want with a "+", and those that differ with both.
+func diffFields(got, want errors.Fields) string {
+	keys := make([]string, 0, len(got)+len(want))
+	for k := range got {
+		keys = append(keys, k)
+	}
+	for k := range want {
+		if _, ok := got[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		g, inGot := got[k]
+		w, inWant := want[k]
+		if inGot && inWant && reflect.DeepEqual(g, w) {
+			continue
+		}
+		if inGot {
+			_, _ = fmt.Fprintf(&b, "  - %s: %#v\n", k, g)
+		}
+		if inWant {
+			_, _ = fmt.Fprintf(&b, "  + %s: %#v\n", k, w)
+		}
+	}
+	return b.String()
+}
+
+// KindName returns name followed by a number that is different on each
+// call. A kind can only be registered once per process, so tests that
+// register kinds name them with KindName, and still pass when they are run
+// again, e.g. with go test -count=2:
+//
+//	quotaKind := errors.NewKind(errtest.KindName("quota exceeded"))
+func KindName(name string) string {
+	return fmt.Sprintf("%s %d", name, kindNames.Add(1))
+}
+
+var kindNames atomic.Int64
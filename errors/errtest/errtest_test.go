@@ -0,0 +1,101 @@
+package errtest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/StevenACoffman/khanerr/errors"
+	"github.com/StevenACoffman/khanerr/errors/errtest"
+)
+
+// fakeT records the failures reported by the assertions, rather than
+// failing the test that is testing them.
+type fakeT struct {
+	testing.TB
+	failures []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+type errtestSuite struct{ suite.Suite }
+
+func (ets *errtestSuite) TestPassing() {
+	inner := errors.NotFound("No such user", errors.Fields{"kaid": "123"})
+	e := errors.Internal("Unable to load", inner, errors.Fields{"attempt": 2})
+
+	t := &fakeT{}
+	ets.Require().True(errtest.AssertKind(t, e, errors.InternalKind))
+	ets.Require().True(errtest.AssertField(t, e, "kaid", "123"))
+	ets.Require().True(errtest.AssertFields(t, e, errors.Fields{"kaid": "123", "attempt": 2}))
+	ets.Require().True(errtest.AssertMessage(t, e, "Unable to load"))
+	ets.Require().True(errtest.AssertWraps(t, e, inner))
+	ets.Require().True(errtest.AssertWraps(t, e, errors.NotFoundKind))
+	ets.Require().True(errtest.AssertNoInvalidArgs(t, e))
+	ets.Require().Empty(t.failures)
+}
+
+func (ets *errtestSuite) TestFailing() {
+	inner := errors.NotFound("No such user", errors.Fields{"kaid": "123"})
+	e := errors.Internal("Unable to load", inner, errors.Fields{"attempt": 2})
+	chain := "error chain:\n" +
+		"  internal error: Unable to load {attempt: 2, kaid: \"123\"}\n" +
+		"  wraps not found: No such user {kaid: \"123\"}\n"
+	ets.Require().Equal(chain, errtest.Chain(e))
+
+	t := &fakeT{}
+	ets.Require().False(errtest.AssertKind(t, e, errors.NotFoundKind))
+	ets.Require().False(errtest.AssertField(t, e, "kaid", "456"))
+	ets.Require().False(errtest.AssertField(t, e, "kiad", "123"))
+	ets.Require().False(errtest.AssertFields(t, e, errors.Fields{"kaid": "456", "video": "abc"}))
+	ets.Require().False(errtest.AssertMessage(t, e, "Oops"))
+	ets.Require().False(errtest.AssertWraps(t, e, errors.UnauthorizedKind))
+	ets.Require().Equal([]string{
+		"error kind: got \"internal error\", want \"not found\"\n" + chain,
+		"error field \"kaid\": got \"123\", want \"456\"\n" + chain,
+		"error field \"kiad\": missing, want \"123\"\n" + chain,
+		"error fields differ (-got +want):\n" +
+			"  - attempt: 2\n" +
+			"  - kaid: \"123\"\n" +
+			"  + kaid: \"456\"\n" +
+			"  + video: \"abc\"\n" + chain,
+		"error message: got \"Unable to load\", want \"Oops\"\n" + chain,
+		"error does not wrap \"unauthorized error\"\n" + chain,
+	}, t.failures)
+}
+
+func (ets *errtestSuite) TestInvalidArgs() {
+	t := &fakeT{}
+	ets.Require().False(errtest.AssertNoInvalidArgs(t, errors.Internal("Message", 42)))
+	ets.Require().Len(t.failures, 1)
+	ets.Require().Contains(t.failures[0], `error has invalid constructor arguments: ["42"]`)
+}
+
+func (ets *errtestSuite) TestNil() {
+	t := &fakeT{}
+	ets.Require().False(errtest.AssertKind(t, nil, errors.NotFoundKind))
+	ets.Require().False(errtest.AssertField(t, nil, "kaid", "123"))
+	ets.Require().False(errtest.AssertWraps(t, nil, errors.NotFoundKind))
+	ets.Require().True(errtest.AssertNoInvalidArgs(t, nil))
+	ets.Require().Len(t.failures, 3)
+	ets.Require().Equal("error chain: <nil>\n", errtest.Chain(nil))
+
+	// Errors that aren't khan errors are described by their Error().
+	ets.Require().Equal(
+		"error chain:\n  unspecified error: plain\n", errtest.Chain(fmt.Errorf("plain")))
+}
+
+func (ets *errtestSuite) TestKindName() {
+	name := errtest.KindName("quota exceeded")
+	ets.Require().Regexp("^quota exceeded [0-9]+$", name)
+	ets.Require().NotEqual(name, errtest.KindName("quota exceeded"))
+}
+
+func TestErrtest(t *testing.T) {
+	suite.Run(t, new(errtestSuite))
+}
@@ -0,0 +1,18 @@
+package errors
+
+import "time"
+
+// Backoff exposes backoff to the tests.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	return p.backoff(attempt)
+}
+
+// SaveRedaction returns a function that puts back the redaction rules as
+// they are now, so tests can register sensitive keys and patterns without
+// affecting the other tests.
+func SaveRedaction() (restore func()) {
+	rules := redaction.Load()
+	return func() {
+		redaction.Store(rules)
+	}
+}
@@ -0,0 +1,51 @@
+package errors
+
+// FieldKey is a field key whose values have type T. Declaring the keys
+// that a package uses once, rather than spelling them out in every
+// Fields{}, means that a typo in a key, or a value of the wrong type, is
+// a compile error rather than a surprise when searching the logs:
+//
+//	var KAID = errors.NewFieldKey[string]("kaid")
+//	...
+//	return errors.NotFound("No such user", KAID.Of(kaid))
+//	...
+//	kaid, ok := KAID.Get(err)
+//
+// Typed fields are stored with the other fields, so GetFields, logging
+// and the other integrations see them like any field.
+type FieldKey[T any] struct {
+	name string
+}
+
+// NewFieldKey returns the key for fields named name, with values of type T.
+func NewFieldKey[T any](name string) FieldKey[T] {
+	return FieldKey[T]{name: name}
+}
+
+// Name returns the name of the field, i.e. its key in GetFields.
+func (k FieldKey[T]) Name() string {
+	return k.name
+}
+
+// Of returns a field with this key and value v. It is an error
+// constructor argument, and any number of fields may be passed. They win
+// over the Fields passed to the same constructor.
+func (k FieldKey[T]) Of(v T) Field {
+	return Field{Key: k.name, Value: v}
+}
+
+// Get returns the value of the field in err, as GetRawFields(err) has it,
+// so sensitive values are not redacted. It returns false if the field
+// isn't set, or its value isn't a T, which can happen if it was set with
+// Fields{} or has been through FromJSON.
+func (k FieldKey[T]) Get(err error) (T, bool) {
+	v, ok := GetRawFields(err)[k.name].(T)
+	return v, ok
+}
+
+// Field is a single field made by FieldKey.Of, for use as an error
+// constructor argument.
+type Field struct {
+	Key   string
+	Value any
+}
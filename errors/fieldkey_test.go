@@ -0,0 +1,51 @@
+package errors_test
+
+import (
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+var (
+	kaidKey    = errors.NewFieldKey[string]("kaid")
+	attemptKey = errors.NewFieldKey[int]("attempt")
+)
+
+func (es *errorSuite) TestFieldKey() {
+	extra := errors.Fields{"kaid": "456", "video": "abc"}
+	e := errors.NotFound("No such user", kaidKey.Of("123"), extra, attemptKey.Of(2))
+	es.Require().Equal("kaid", kaidKey.Name())
+	es.Require().Equal(errors.Fields{
+		"Kind":    "not found",
+		"Message": "No such user",
+		// Typed fields win over Fields.
+		"kaid":    "123",
+		"attempt": 2,
+		"video":   "abc",
+	}, errors.GetFields(e))
+	es.Require().NotContains(errors.GetFields(e), errors.InvalidErrArgsKey)
+	es.Require().Equal(errors.Fields{"kaid": "456", "video": "abc"}, extra)
+
+	kaid, ok := kaidKey.Get(e)
+	es.Require().True(ok)
+	es.Require().Equal("123", kaid)
+
+	// Typed fields are kept when the error is wrapped, like other fields.
+	outer := errors.Internal("Unable to load", e, attemptKey.Of(3))
+	attempt, ok := attemptKey.Get(outer)
+	es.Require().True(ok)
+	es.Require().Equal(3, attempt)
+	kaid, ok = kaidKey.Get(outer)
+	es.Require().True(ok)
+	es.Require().Equal("123", kaid)
+}
+
+func (es *errorSuite) TestFieldKeyMissing() {
+	_, ok := kaidKey.Get(errors.NotFound())
+	es.Require().False(ok)
+	_, ok = kaidKey.Get(nil)
+	es.Require().False(ok)
+
+	// A value of another type, e.g. set with Fields{}, isn't returned.
+	attempt, ok := attemptKey.Get(errors.Internal(errors.Fields{"attempt": "two"}))
+	es.Require().False(ok)
+	es.Require().Zero(attempt)
+}
@@ -0,0 +1,163 @@
+package errors
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultFingerprintFrames is how many stack frames Fingerprint uses.
+const DefaultFingerprintFrames = 5
+
+// FingerprintOptions controls what FingerprintWithOptions takes into
+// account.
+type FingerprintOptions struct {
+	// Frames is how many of the innermost stack frames to use, after
+	// leaving out ExcludeFrames. Zero means DefaultFingerprintFrames, and
+	// a negative number means none.
+	Frames int
+
+	// ExcludeFrames lists prefixes of function names, like
+	// "github.com/myorg/myapp/middleware.", whose frames are left out,
+	// e.g. because they are shared by unrelated errors.
+	ExcludeFrames []string
+
+	// ExcludeKeys lists field keys that are left out, e.g. because they
+	// are only set some of the time, like request IDs.
+	ExcludeKeys []string
+}
+
+func (o *FingerprintOptions) frames() int {
+	if o == nil || o.Frames == 0 {
+		return DefaultFingerprintFrames
+	}
+	return o.Frames
+}
+
+func (o *FingerprintOptions) includesFrame(function string) bool {
+	if o == nil {
+		return true
+	}
+	for _, prefix := range o.ExcludeFrames {
+		if strings.HasPrefix(function, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func (o *FingerprintOptions) includesKey(key string) bool {
+	if key == KindKey || key == MessageKey {
+		// These are already taken from each error in the chain.
+		return false
+	}
+	if o == nil {
+		return true
+	}
+	for _, excluded := range o.ExcludeKeys {
+		if key == excluded {
+			return false
+		}
+	}
+	return true
+}
+
+// messageValues matches the parts of a message that are likely to vary
+// between errors from the same place: quoted strings, UUIDs, hex and
+// decimal numbers.
+var messageValues = regexp.MustCompile(
+	`"[^"]*"|'[^']*'|` +
+		`[0-9a-fA-F]{8}(?:-[0-9a-fA-F]{4}){3}-[0-9a-fA-F]{12}|` +
+		`0[xX][0-9a-fA-F]+|\d+(?:\.\d+)?`)
+
+// messageTemplate returns message with the parts that are likely to vary
+// replaced by "?", so that "No user 123" and "No user 456" are the same.
+func messageTemplate(message string) string {
+	return messageValues.ReplaceAllString(message, "?")
+}
+
+// Fingerprint returns a short, stable hash of err for grouping errors in
+// dashboards and error trackers. It is the same as
+// FingerprintWithOptions(err, nil).
+func Fingerprint(err error) string {
+	return FingerprintWithOptions(err, nil)
+}
+
+// FingerprintWithOptions returns a hash of:
+//
+//   - the kind of each error in the chain, and the type of any non-khan
+//     error in it;
+//   - each message, with numbers, UUIDs and quoted strings taken out;
+//   - the keys, but not the values, of the error's fields;
+//   - the function names of the innermost stack frames.
+//
+// So errors from the same code path get the same fingerprint even if
+// their field values differ, while errors from different places don't.
+// Only function names are used from the stack, not file paths or line
+// numbers, so the fingerprint is the same across runs, across builds in
+// different directories or with -trimpath, and across edits that merely
+// move code around. It returns "" for a nil error.
+func FingerprintWithOptions(err error, opts *FingerprintOptions) string {
+	if err == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	writeFingerprintChain(&buf, err)
+
+	var keys []string
+	for k := range rawFields(err) {
+		if opts.includesKey(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	_, _ = fmt.Fprintf(&buf, "keys %s\n", strings.Join(keys, ","))
+
+	if n := opts.frames(); n > 0 {
+		for _, frame := range GetStackTrace(err) {
+			if !opts.includesFrame(frame.Function) {
+				continue
+			}
+			_, _ = fmt.Fprintf(&buf, "frame %s\n", frame.Function)
+			if n--; n == 0 {
+				break
+			}
+		}
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:16])
+}
+
+// writeFingerprintChain writes a line for each error in the chain of err,
+// with its kind (or type) and message template.
+func writeFingerprintChain(buf *bytes.Buffer, err error) {
+	for c := err; c != nil; {
+		switch v := c.(type) {
+		case *Error:
+			_, _ = fmt.Fprintf(buf, "kind %s %s\n", v.kind, messageTemplate(v.message))
+			c = v.Cause()
+		case *joinError:
+			_, _ = fmt.Fprintf(buf, "join %s\n", v.kind)
+			for _, joined := range v.errs {
+				writeFingerprintChain(buf, joined)
+				buf.WriteString("end\n")
+			}
+			c = nil
+		default:
+			next := Unwrap(c)
+			if next == nil {
+				// Only the innermost message is used, since the errors
+				// that wrap it usually repeat it.
+				_, _ = fmt.Fprintf(buf, "type %T %s\n", c, messageTemplate(c.Error()))
+			} else {
+				_, _ = fmt.Fprintf(buf, "type %T\n", c)
+			}
+			c = next
+		}
+	}
+}
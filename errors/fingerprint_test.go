@@ -0,0 +1,65 @@
+package errors_test
+
+import (
+	"fmt"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+func findUser(kaid string) error {
+	return errors.NotFound(fmt.Sprintf("No user %q", kaid), errors.Fields{"kaid": kaid})
+}
+
+// findOtherUser makes the same error as findUser, from somewhere else.
+func findOtherUser(kaid string) error {
+	return errors.NotFound(fmt.Sprintf("No user %q", kaid), errors.Fields{"kaid": kaid})
+}
+
+func (es *errorSuite) TestFingerprint() {
+	// Field values and the variable parts of messages don't matter.
+	es.Require().Equal(errors.Fingerprint(findUser("123")), errors.Fingerprint(findUser("456")))
+	es.Require().Len(errors.Fingerprint(findUser("123")), 32)
+	es.Require().Empty(errors.Fingerprint(nil))
+
+	// The code path does.
+	es.Require().NotEqual(errors.Fingerprint(findUser("123")), errors.Fingerprint(findOtherUser("123")))
+	es.Require().NotEqual(
+		errors.Fingerprint(findUser("123")),
+		errors.Fingerprint(errors.Internal(findUser("123"))))
+	es.Require().NotEqual(
+		errors.Fingerprint(findUser("123")),
+		errors.Fingerprint(errors.Wrap(findUser("123"), "attempt", 2)))
+
+	// As do the field keys.
+	es.Require().NotEqual(
+		errors.Fingerprint(errors.NotFound(errors.Fields{"kaid": "1"})),
+		errors.Fingerprint(errors.NotFound(errors.Fields{"slug": "1"})))
+}
+
+func (es *errorSuite) TestFingerprintStable() {
+	// Without stack frames, the fingerprint only depends on the error, so
+	// it is the same in every build.
+	opts := &errors.FingerprintOptions{Frames: -1}
+	e := errors.Internal("Unable to load 3 profiles", findUser("123"),
+		errors.Fields{"attempt": 2})
+	es.Require().Equal("8d63893c9eb9537dab49fe1d2cedc730", errors.FingerprintWithOptions(e, opts))
+}
+
+func (es *errorSuite) TestFingerprintOptions() {
+	// Excluding the frames of the functions that made the errors leaves
+	// only the frames they share.
+	opts := &errors.FingerprintOptions{ExcludeFrames: []string{
+		"github.com/StevenACoffman/khanerr/errors_test.find",
+	}}
+	es.Require().Equal(
+		errors.FingerprintWithOptions(findUser("123"), opts),
+		errors.FingerprintWithOptions(findOtherUser("123"), opts))
+
+	opts = &errors.FingerprintOptions{ExcludeKeys: []string{"requestID"}}
+	es.Require().Equal(
+		errors.FingerprintWithOptions(errors.Internal(), opts),
+		errors.FingerprintWithOptions(errors.Internal(errors.Fields{"requestID": "abc"}), opts))
+	es.Require().NotEqual(
+		errors.Fingerprint(errors.Internal()),
+		errors.Fingerprint(errors.Internal(errors.Fields{"requestID": "abc"})))
+}
@@ -0,0 +1,207 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"sort"
+
+	simpler "github.com/StevenACoffman/simplerr/errors"
+)
+
+// Format implements fmt.Formatter:
+//
+//	%s   is the same as Error(), which has every field, for the logs.
+//	%q   is Error() quoted.
+//	%v   is a concise one-liner of the kind and the messages in the chain,
+//	     like "internal error: Unable to load: No such user".
+//	%+v  is the whole chain, innermost error first, with one "Wrapped by:"
+//	     paragraph per error holding its kind, message, the fields it
+//	     added or changed, and the stack frames it added.
+//	%#v  is a Go-syntax dump of the error and the errors it wraps.
+func (e *Error) Format(st fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && st.Flag('#'):
+		_, _ = io.WriteString(st, e.goString())
+	case verb == 'v' && st.Flag('+'):
+		_, _ = io.WriteString(st, e.chainString())
+	case verb == 'v':
+		_, _ = io.WriteString(st, e.shortString())
+	case verb == 'q':
+		_, _ = fmt.Fprintf(st, "%q", e.Error())
+	default:
+		_, _ = io.WriteString(st, e.Error())
+	}
+}
+
+// shortString returns the kind of the error, followed by the messages of
+// every error in the chain that has one.
+func (e *Error) shortString() string {
+	if e == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	buf.WriteString(string(getKind(e)))
+	var c error = e
+	for c != nil {
+		var message string
+		switch v := c.(type) {
+		case *Error:
+			message = v.message
+			c = v.wrappedErr
+			if c == v.kind {
+				c = nil
+			}
+		case *joinError:
+			message, _ = v.fields()[MessageKey].(string)
+			c = nil
+		default:
+			message = v.Error()
+			c = nil
+		}
+		if message != "" {
+			buf.WriteString(": ")
+			buf.WriteString(message)
+		}
+	}
+	return buf.String()
+}
+
+// chainString returns a longer, multi-line error message, with one
+// paragraph for each khan error in the chain, starting with the innermost.
+// We expose the metadata (except some special empty fields) that each
+// error added to the error it wraps, and the stack frames that it added.
+func (e *Error) chainString() string {
+	if e == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	e.writeChain(&buf)
+	return buf.String()
+}
+
+// writeChain writes the chainString of e to buf, and returns the frames
+// of e's stack trace, so that the error that wraps e can leave out the
+// frames they share.
+func (e *Error) writeChain(buf *bytes.Buffer) []runtime.Frame {
+	// TODO(csilvers): do we want to do something special if the
+	// wrapped error is a sentinel?  Like maybe show our error text
+	// above the sentinel, followed by a special "Wraps sentinel:"
+	// line.  (We can tell if we're a sentinel based on `source`
+	// having `"init"`.)
+	// TODO(csilvers): similarly for non-khan errors: we may want to
+	// show the first khan-error instead, that wraps the non-khan error.
+	var innerFrames []runtime.Frame
+	var innerFields Fields
+	if e.wrappedErr != nil && e.wrappedErr != e.kind {
+		innerFields = rawFields(e.wrappedErr)
+		if inner, ok := e.wrappedErr.(*Error); ok {
+			innerFrames = inner.writeChain(buf)
+		} else {
+			buf.WriteString(e.wrappedErr.Error())
+			innerFrames = stackFrames(e.wrappedErr)
+			writeFrames(buf, innerFrames)
+		}
+		buf.WriteString("\nWrapped by: ")
+	}
+
+	_, _ = fmt.Fprintf(buf, "%s", string(getKind(e)))
+	if e.message != "" {
+		_, _ = fmt.Fprintf(buf, " %s", e.message)
+	}
+	keys := make([]string, 0, len(e.extra))
+	for k, v := range e.extra {
+		// Wrap copies the kind, message and fields of the wrapped error
+		// into the fields; they are on the wrapped error's own line
+		// already, so only show the ones that were added or changed.
+		if k == KindKey || k == MessageKey {
+			continue
+		}
+		if inner, ok := innerFields[k]; ok && reflect.DeepEqual(inner, v) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	extra := redactFields(e.extra)
+	for _, k := range keys {
+		fieldValue := StringifyField(extra[k])
+		// Ignore empty fields for special keys. These keys are set by
+		// RecoverValue and the graphql error handler with empty values
+		// to ensure that the fields are present in the log schema and
+		// thus avoid log export problems. But if they are empty we
+		// don't need to see them in the message.
+		if fieldValue == "" && isPanicKey(k) {
+			continue
+		}
+		_, _ = fmt.Fprintf(buf, ", %s = %s", k, fieldValue)
+	}
+	frames := stackFrames(e.flat)
+	writeFrames(buf, elideSharedFrames(innerFrames, frames))
+	return frames
+}
+
+// stackTracer is implemented by the simpler errors that record a stack
+// trace.
+type stackTracer interface {
+	StackTrace() *simpler.StackTrace
+}
+
+// stackFrames returns the frames of the stack trace of err itself, not
+// of the errors it wraps, or nil if it has none.
+func stackFrames(err error) []runtime.Frame {
+	st, ok := err.(stackTracer)
+	if !ok {
+		return nil
+	}
+	return traceFrames(st.StackTrace())
+}
+
+// traceFrames returns all the frames of trace, including the last one,
+// which Next returns along with more == false.
+func traceFrames(trace *simpler.StackTrace) []runtime.Frame {
+	var frames []runtime.Frame
+	for {
+		frame, more := trace.Next()
+		frames = append(frames, frame)
+		if !more {
+			return frames
+		}
+	}
+}
+
+// elideSharedFrames returns frames without the outermost calls that it
+// has in common with inner, leaving the calls that lead from the inner
+// error to the one that wraps it. At least one frame is always kept.
+func elideSharedFrames(inner, frames []runtime.Frame) []runtime.Frame {
+	i, j := len(inner)-1, len(frames)-1
+	for i >= 0 && j > 0 && inner[i].PC == frames[j].PC {
+		i--
+		j--
+	}
+	return frames[:j+1]
+}
+
+// writeFrames writes one indented line for the function, and one for the
+// file and line, of each frame.
+func writeFrames(buf *bytes.Buffer, frames []runtime.Frame) {
+	for _, frame := range frames {
+		_, _ = fmt.Fprintf(buf, "\n\t%s\n\t\t%s:%d", frame.Function, frame.File, frame.Line)
+	}
+}
+
+// goString returns the error as Go syntax, for debugging.
+func (e *Error) goString() string {
+	if e == nil {
+		return "(*errors.Error)(nil)"
+	}
+	extra := e.extra
+	if extra != nil {
+		extra = redactFields(extra)
+	}
+	return fmt.Sprintf(
+		"&errors.Error{kind:%q, message:%q, public:%q, severity:%v, source:%q, extra:%#v, wrappedErr:%#v}",
+		string(e.kind), e.message, e.public, e.severity, e.source, extra, e.wrappedErr)
+}
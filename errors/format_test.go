@@ -0,0 +1,101 @@
+package errors_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// fileLine matches the file:line of a frame in a %+v stack trace.
+var fileLine = regexp.MustCompile(`^\t\t.*?([^/]+\.(?:go|s)):\d+$`)
+
+// normalizeFrames keeps only the frames in a %+v stack trace that are in
+// this module, without their directories or line numbers, so that the
+// golden files don't depend on where the module is or on testify.
+func normalizeFrames(s string) string {
+	lines := strings.Split(s, "\n")
+	var kept []string
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "\t\t") &&
+			i+1 < len(lines) && fileLine.MatchString(lines[i+1]) {
+			if strings.Contains(line, "khanerr") {
+				kept = append(kept, line, fileLine.ReplaceAllString(lines[i+1], "\t\t$1"))
+			}
+			i++
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+func loadUser() error {
+	return errors.NotFound("No such user", errors.Fields{"kaid": "123"})
+}
+
+func loadProfile() error {
+	return errors.Internal("Unable to load profile", loadUser(), errors.Fields{"attempt": 2})
+}
+
+// formatCases are the errors in the golden files, in order.
+func formatCases() []struct {
+	name string
+	err  error
+} {
+	return []struct {
+		name string
+		err  error
+	}{
+		{"simple", loadUser()},
+		{"chain", loadProfile()},
+		{"non-khan cause", errors.Service(fmt.Errorf("connection reset"))},
+		{"wrap", errors.Wrap(loadUser(), "attempt", 3)},
+		{"wrap override", errors.Wrap(loadUser(), "kaid", "456")},
+		{"empty", errors.Unauthorized()},
+		{"public", errors.NotFound("No user 123", errors.Public("No such user"), errors.SeverityWarning)},
+	}
+}
+
+// checkGolden formats every case with format and compares the output
+// with testdata/name.golden.
+func (es *errorSuite) checkGolden(name, format string) {
+	var buf strings.Builder
+	for _, c := range formatCases() {
+		_, _ = fmt.Fprintf(&buf, "-- %s --\n%s\n", c.name,
+			normalizeFrames(fmt.Sprintf(format, c.err)))
+	}
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		es.Require().NoError(os.MkdirAll("testdata", 0o755))
+		es.Require().NoError(os.WriteFile(path, []byte(buf.String()), 0o600))
+	}
+	want, err := os.ReadFile(path)
+	es.Require().NoError(err)
+	es.Require().Equal(string(want), buf.String())
+}
+
+func (es *errorSuite) TestFormatV() {
+	es.checkGolden("format_v", "%v")
+}
+
+func (es *errorSuite) TestFormatPlusV() {
+	es.checkGolden("format_plus_v", "%+v")
+}
+
+func (es *errorSuite) TestFormatSharpV() {
+	es.checkGolden("format_sharp_v", "%#v")
+}
+
+func (es *errorSuite) TestFormatS() {
+	e := loadUser()
+	es.Require().Equal(e.Error(), fmt.Sprintf("%s", e))
+	es.Require().Equal(fmt.Sprintf("%q", e.Error()), fmt.Sprintf("%q", e))
+}
@@ -0,0 +1,140 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// ProblemContentType is the media type of RFC 9457 problem details.
+const ProblemContentType = "application/problem+json"
+
+// HTTPStatusKey is the field that ParseProblem uses to record the HTTP
+// status of the problem.
+const HTTPStatusKey = "httpStatus"
+
+// httpStatuses is the HTTP status used for each kind. UnauthorizedKind is
+// a 403 rather than a 401 since it means an access control problem, not
+// missing credentials. The service kinds are about a remote system
+// failing, so they are gateway errors.
+var httpStatuses = newKindTable(coreHTTPStatuses)
+
+// RegisterHTTPStatus sets the HTTP status used for errors of kind, which
+// is typically a kind added with RegisterKind. Kinds without a status are
+// served as a 500.
+func RegisterHTTPStatus(kind errorKind, status int) {
+	httpStatuses.set(kind, status)
+}
+
+// HTTPStatus returns the HTTP status for the kind of err, or 200 if err is
+// nil.
+func HTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	if status, ok := httpStatuses.get(GetKind(err)); ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// kindForHTTPStatus is the reverse of HTTPStatus, for responses that don't
+// say what kind of error they are.
+func kindForHTTPStatus(status int) errorKind {
+	if kind, ok := kindsByHTTPStatus[status]; ok {
+		return kind
+	}
+	if status >= 400 && status < 500 {
+		return InvalidInputKind
+	}
+	return InternalKind
+}
+
+// Problem is an RFC 9457 problem details object, extended with the kind
+// and the fields of the error it describes.
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+	Fields   Fields `json:"fields,omitempty"`
+}
+
+// NewProblem describes err as a Problem, whose Detail is the
+// PublicMessage of err rather than its own message. Only the fields named
+// in allowedFields are included, since most fields are meant for our logs
+// rather than for clients.
+func NewProblem(err error, allowedFields ...string) *Problem {
+	status := HTTPStatus(err)
+	problem := &Problem{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: PublicMessage(err),
+		Kind:   GetKind(err).String(),
+	}
+	fields := GetFields(err)
+	for _, key := range allowedFields {
+		if v, ok := fields[key]; ok {
+			if problem.Fields == nil {
+				problem.Fields = Fields{}
+			}
+			problem.Fields[key] = jsonSafeField(v)
+		}
+	}
+	return problem
+}
+
+// WriteProblem writes err to w as an application/problem+json response,
+// with the HTTP status for its kind and its PublicMessage. Only the fields
+// named in allowedFields are included in the response. It writes nothing
+// if err is nil, so that the handler can write its successful response.
+func WriteProblem(w http.ResponseWriter, err error, allowedFields ...string) {
+	if err == nil {
+		return
+	}
+	problem := NewProblem(err, allowedFields...)
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	_ = json.NewEncoder(w).Encode(problem)
+}
+
+// ParseProblem reads a problem+json response body and returns the error
+// it describes. The kind is taken from the problem's "kind" member if
+// it's a known kind, and otherwise from its status. The problem's fields
+// are kept, along with its status under HTTPStatusKey.
+//
+// If body can't be parsed, an Internal error saying so is returned.
+func ParseProblem(body io.Reader) error {
+	var problem Problem
+	if err := json.NewDecoder(body).Decode(&problem); err != nil {
+		return Internal("Unable to parse problem+json response", err)
+	}
+	kind, ok := LookupKind(problem.Kind)
+	if !ok || kind == UnspecifiedKind {
+		kind = kindForHTTPStatus(problem.Status)
+	}
+	fields := Fields{}
+	for k, v := range problem.Fields {
+		fields[k] = v
+	}
+	if problem.Status != 0 {
+		fields[HTTPStatusKey] = problem.Status
+	}
+	message := problem.Detail
+	if message == "" {
+		message = problem.Title
+	}
+	return newError(kind, message, fields)
+}
+
+// jsonSafeField returns value if it can be encoded as JSON, and otherwise
+// its StringifyField form, so one odd field can't break the encoding.
+func jsonSafeField(value any) any {
+	if _, err := json.Marshal(value); err != nil {
+		return StringifyField(value)
+	}
+	return value
+}
@@ -0,0 +1,89 @@
+package errors_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/StevenACoffman/khanerr/errors"
+	"github.com/StevenACoffman/khanerr/errors/errtest"
+)
+
+func (es *errorSuite) TestHTTPStatus() {
+	es.Require().Equal(http.StatusOK, errors.HTTPStatus(nil))
+	es.Require().Equal(http.StatusNotFound, errors.HTTPStatus(errors.NotFound()))
+	es.Require().Equal(http.StatusBadRequest, errors.HTTPStatus(errors.InvalidInput()))
+	es.Require().Equal(http.StatusConflict, errors.HTTPStatus(errors.NotAllowed()))
+	es.Require().Equal(http.StatusForbidden, errors.HTTPStatus(errors.Unauthorized()))
+	es.Require().Equal(http.StatusInternalServerError, errors.HTTPStatus(errors.Internal()))
+	es.Require().Equal(http.StatusNotImplemented, errors.HTTPStatus(errors.NotImplemented()))
+	es.Require().
+		Equal(http.StatusServiceUnavailable, errors.HTTPStatus(errors.TransientService()))
+	es.Require().Equal(http.StatusBadGateway, errors.HTTPStatus(errors.Service()))
+	es.Require().Equal(http.StatusInternalServerError, errors.HTTPStatus(fmt.Errorf("plain")))
+
+	tooManyKind := errors.NewKind(errtest.KindName("http too many requests"))
+	es.Require().Equal(http.StatusInternalServerError, errors.HTTPStatus(tooManyKind.New()))
+	errors.RegisterHTTPStatus(tooManyKind, http.StatusTooManyRequests)
+	es.Require().Equal(http.StatusTooManyRequests, errors.HTTPStatus(tooManyKind.New()))
+}
+
+func (es *errorSuite) TestWriteProblem() {
+	e := errors.NotFound("No such video",
+		errors.Fields{"video": "abc", "kaid": "123", "ch": make(chan int)})
+	rec := httptest.NewRecorder()
+	errors.WriteProblem(rec, e, "video", "ch", "missing")
+
+	es.Require().Equal(http.StatusNotFound, rec.Code)
+	es.Require().Equal(errors.ProblemContentType, rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	es.Require().JSONEq(`{
+		"title": "Not Found",
+		"status": 404,
+		"detail": "Not found",
+		"kind": "not found",
+		"fields": {"video": "abc", "ch": "`+errors.StringifyField(errors.GetFields(e)["ch"])+`"}
+	}`, body)
+	es.Require().NotContains(body, "kaid")
+	es.Require().NotContains(body, "No such video")
+}
+
+func (es *errorSuite) TestWriteProblemNil() {
+	rec := httptest.NewRecorder()
+	errors.WriteProblem(rec, nil)
+	es.Require().Empty(rec.Header())
+	es.Require().Zero(rec.Body.Len())
+
+	// The handler can still write its own response.
+	rec.WriteHeader(http.StatusCreated)
+	es.Require().Equal(http.StatusCreated, rec.Code)
+}
+
+func (es *errorSuite) TestParseProblem() {
+	rec := httptest.NewRecorder()
+	errors.WriteProblem(rec, errors.NotAllowed("Username sal exists", errors.Public("Username taken"),
+		errors.Fields{"username": "sal"}), "username")
+
+	e := errors.ParseProblem(rec.Body)
+	es.Require().Equal(errors.NotAllowedKind, errors.GetKind(e))
+	es.Require().Equal(errors.Fields{
+		"Kind":               "not allowed",
+		"Message":            "Username taken",
+		"username":           "sal",
+		errors.HTTPStatusKey: http.StatusConflict,
+	}, errors.GetFields(e))
+
+	// Problems from elsewhere get a kind from their status.
+	e = errors.ParseProblem(strings.NewReader(
+		`{"type": "https://example.com/probs/out-of-credit", "title": "Out of credit", "status": 403}`))
+	es.Require().Equal(errors.UnauthorizedKind, errors.GetKind(e))
+	es.Require().Equal("Out of credit", errors.GetFields(e)[errors.MessageKey])
+	e = errors.ParseProblem(strings.NewReader(`{"status": 401, "kind": "no such kind"}`))
+	es.Require().Equal(errors.UnauthorizedKind, errors.GetKind(e))
+	e = errors.ParseProblem(strings.NewReader(`{"status": 429}`))
+	es.Require().Equal(errors.TransientServiceKind, errors.GetKind(e))
+
+	e = errors.ParseProblem(strings.NewReader(`<html>oops</html>`))
+	es.Require().Equal(errors.InternalKind, errors.GetKind(e))
+}
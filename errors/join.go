@@ -0,0 +1,183 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+
+	simpler "github.com/StevenACoffman/simplerr/errors"
+)
+
+// KindStrategy says how Combine picks the kind of the combined error from
+// the kinds of the errors being combined.
+type KindStrategy int
+
+const (
+	// MostSevereKind picks the kind that most needs attention, so that
+	// e.g. an InternalKind error isn't hidden by a handful of NotFoundKind
+	// errors. Kinds added with RegisterKind are the least severe.
+	MostSevereKind KindStrategy = iota
+	// MostCommonKind picks the kind that the most errors have.
+	MostCommonKind
+	// FirstKind picks the kind of the first error.
+	FirstKind
+)
+
+// registeredKindRank is the rank of kinds added with RegisterKind, for
+// MostSevereKind.
+const registeredKindRank = 1
+
+func (s KindStrategy) pick(kinds []errorKind) errorKind {
+	if len(kinds) == 0 {
+		return UnspecifiedKind
+	}
+	// Errors that aren't khan errors count as InternalKind, which is what
+	// Wrap would give them.
+	for i, kind := range kinds {
+		if kind == UnspecifiedKind {
+			kinds[i] = InternalKind
+		}
+	}
+	switch s {
+	case FirstKind:
+		return kinds[0]
+	case MostCommonKind:
+		counts := map[errorKind]int{}
+		picked := kinds[0]
+		for _, kind := range kinds {
+			counts[kind]++
+			// Ties go to the kind that was seen first.
+			if counts[kind] > counts[picked] {
+				picked = kind
+			}
+		}
+		return picked
+	default:
+		severity := func(kind errorKind) int {
+			if sev, ok := kindRanks[kind]; ok {
+				return sev
+			}
+			return registeredKindRank
+		}
+		picked := kinds[0]
+		for _, kind := range kinds[1:] {
+			if severity(kind) > severity(picked) {
+				picked = kind
+			}
+		}
+		return picked
+	}
+}
+
+// joinError is an error made of several errors, like the one returned by
+// the stdlib errors.Join, but with a kind.
+type joinError struct {
+	kind  errorKind
+	errs  []error
+	stack *simpler.Stack
+}
+
+// Join combines errs into one error, whose kind is the most severe kind
+// among them. It is the same as Combine(MostSevereKind, errs...).
+func Join(errs ...error) error {
+	return combine(MostSevereKind, errs)
+}
+
+// Combine returns an error that wraps all the non-nil errors in errs, or
+// nil if there are none. It is useful for batch operations, which may fail
+// for several reasons at once.
+//
+// The kind of the combined error is picked from the kinds of errs
+// according to strategy, with errors that aren't khan errors counting as
+// InternalKind. errors.Is matches the kind of every one of errs, though,
+// as well as the errors themselves. errors.As, and the stdlib
+// errors.Is and errors.As, also look through all of errs. GetFields
+// merges the fields of all of errs, with later errors winning, and sets
+// Kind to the combined kind.
+func Combine(strategy KindStrategy, errs ...error) error {
+	return combine(strategy, errs)
+}
+
+func combine(strategy KindStrategy, errs []error) error {
+	var nonNil []error
+	var kinds []errorKind
+	for _, err := range errs {
+		if err != nil {
+			nonNil = append(nonNil, err)
+			kinds = append(kinds, GetKind(err))
+		}
+	}
+	if len(nonNil) == 0 {
+		return nil
+	}
+	return &joinError{
+		kind: strategy.pick(kinds),
+		errs: nonNil,
+		// Start the stack trace at the caller of Join or Combine.
+		stack: simpler.Callers(3),
+	}
+}
+
+// Error lists each of the errors on its own line.
+func (e *joinError) Error() string {
+	var buf bytes.Buffer
+	_, _ = fmt.Fprintf(&buf, "%s: %d errors occurred:", e.kind, len(e.errs))
+	for _, err := range e.errs {
+		buf.WriteString("\n\t* ")
+		buf.WriteString(err.Error())
+	}
+	return buf.String()
+}
+
+// StackTrace returns where the errors were combined.
+func (e *joinError) StackTrace() *simpler.StackTrace {
+	return e.stack.StackTrace()
+}
+
+// Unwrap returns the combined errors, for the stdlib errors.Is and As.
+func (e *joinError) Unwrap() []error {
+	errs := make([]error, len(e.errs))
+	copy(errs, e.errs)
+	return errs
+}
+
+// Is matches the combined kind, and anything that any of the combined
+// errors matches.
+func (e *joinError) Is(target error) bool {
+	if e.kind == target {
+		return true
+	}
+	for _, err := range e.errs {
+		if Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the combined errors that matches target. If
+// target is a kind, it is set to the combined kind instead.
+func (e *joinError) As(target any) bool {
+	if kind, ok := target.(*errorKind); ok {
+		*kind = e.kind
+		return true
+	}
+	for _, err := range e.errs {
+		if As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// fields merges the raw fields of the combined errors.
+func (e *joinError) fields() Fields {
+	fields := Fields{}
+	for _, err := range e.errs {
+		for k, v := range rawFields(err) {
+			fields[k] = v
+		}
+	}
+	fields[KindKey] = string(e.kind)
+	fields[MessageKey] = fmt.Sprintf("%d errors occurred", len(e.errs))
+	return fields
+}
@@ -0,0 +1,91 @@
+package errors_test
+
+import (
+	stderrors "errors"
+	"fmt"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+func (es *errorSuite) TestJoin() {
+	notFound := errors.NotFound("No such user", errors.Fields{"kaid": "123"})
+	internal := errors.Internal("Datastore broke", errors.Fields{"kaid": "456", "table": "users"})
+	plain := &_error{}
+
+	e := errors.Join(notFound, nil, internal, plain)
+	es.Require().Equal(errors.InternalKind, errors.GetKind(e))
+	es.Require().True(errors.IsKhanError(e))
+
+	es.Require().True(errors.Is(e, errors.InternalKind))
+	es.Require().True(errors.Is(e, errors.NotFoundKind))
+	es.Require().False(errors.Is(e, errors.UnauthorizedKind))
+	es.Require().True(errors.Is(e, notFound))
+	es.Require().True(errors.Is(e, plain))
+	es.Require().True(stderrors.Is(e, errors.NotFoundKind))
+	es.Require().True(stderrors.Is(e, plain))
+
+	var target *_error
+	es.Require().True(errors.As(e, &target))
+	es.Require().Equal(plain, target)
+	target = nil
+	es.Require().True(stderrors.As(e, &target))
+
+	es.Require().Equal(errors.Fields{
+		"Kind":    "internal error",
+		"Message": "3 errors occurred",
+		"kaid":    "456",
+		"table":   "users",
+	}, errors.GetFields(e))
+
+	es.Require().Equal("internal error: 3 errors occurred:\n\t* "+
+		notFound.Error()+"\n\t* "+internal.Error()+"\n\t* ", e.Error())
+
+	es.Require().NotEmpty(errors.GetStackTrace(e))
+	es.Require().Equal(errors.Source("errors_test.(*errorSuite).TestJoin"),
+		errors.GetSource(errors.Join(fmt.Errorf("a"))))
+}
+
+func (es *errorSuite) TestJoinPlainErrors() {
+	e := errors.Join(fmt.Errorf("a"), fmt.Errorf("b"))
+	es.Require().Equal(errors.InternalKind, errors.GetKind(e))
+
+	// Wrapping keeps the fields, rather than failing for lack of a kind.
+	wrapped := errors.Wrap(e, "k", 1)
+	es.Require().Equal(errors.InternalKind, errors.GetKind(wrapped))
+	es.Require().Equal(1, errors.GetFields(wrapped)["k"])
+	es.Require().True(errors.Is(wrapped, e))
+}
+
+func (es *errorSuite) TestJoinNil() {
+	es.Require().NoError(errors.Join())
+	es.Require().NoError(errors.Join(nil, nil))
+}
+
+func (es *errorSuite) TestCombine() {
+	errs := []error{
+		errors.NotFound(),
+		errors.InvalidInput(),
+		errors.InvalidInput(),
+		errors.Unauthorized(),
+	}
+	es.Require().Equal(errors.UnauthorizedKind,
+		errors.GetKind(errors.Combine(errors.MostSevereKind, errs...)))
+	es.Require().Equal(errors.InvalidInputKind,
+		errors.GetKind(errors.Combine(errors.MostCommonKind, errs...)))
+	es.Require().Equal(errors.NotFoundKind,
+		errors.GetKind(errors.Combine(errors.FirstKind, errs...)))
+
+	plain := []error{errors.NotFound(), fmt.Errorf("a"), fmt.Errorf("b")}
+	es.Require().Equal(errors.InternalKind,
+		errors.GetKind(errors.Combine(errors.MostCommonKind, plain...)))
+	es.Require().Equal(errors.InternalKind,
+		errors.GetKind(errors.Combine(errors.MostSevereKind, plain...)))
+	es.Require().Equal(errors.InternalKind,
+		errors.GetKind(errors.Combine(errors.FirstKind, plain[1:]...)))
+
+	// Wrapping a combined error keeps its kind and fields.
+	e := errors.Wrap(errors.Combine(errors.FirstKind, errs...), "batch", 7)
+	es.Require().Equal(errors.NotFoundKind, errors.GetKind(e))
+	es.Require().Equal(7, errors.GetFields(e)["batch"])
+	es.Require().True(errors.Is(e, errors.UnauthorizedKind))
+}
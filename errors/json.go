@@ -0,0 +1,111 @@
+package errors
+
+import (
+	"encoding/json"
+)
+
+// jsonError is how an error is encoded by MarshalJSON.
+type jsonError struct {
+	Kind    string   `json:"kind"`
+	Message string   `json:"message,omitempty"`
+	Fields  Fields   `json:"fields,omitempty"`
+	Causes  []Fields `json:"causes,omitempty"`
+}
+
+// MarshalJSON encodes the kind, message and fields of the error, along
+// with the fields of the errors it wraps (see GetWrappedErrors), so that
+// it can be sent to another process and rebuilt with FromJSON. Fields that
+// can't be encoded as JSON are encoded using StringifyField, and sensitive
+// values are redacted.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	fields := GetFields(e)
+	encoded := jsonError{Kind: GetKind(e).String()}
+	if message, ok := fields[MessageKey].(string); ok {
+		encoded.Message = message
+	}
+	delete(fields, KindKey)
+	delete(fields, MessageKey)
+	encoded.Fields = jsonSafeFields(fields)
+	for _, cause := range e.wrappedErrors() {
+		encoded.Causes = append(encoded.Causes, jsonSafeFields(redactFields(cause)))
+	}
+	return json.Marshal(encoded)
+}
+
+// FromJSON rebuilds an error from the output of MarshalJSON. The error has
+// the same kind, so errors.Is(err, NotFoundKind) still works, and the same
+// message and fields. The causes become a chain of wrapped errors, which
+// are khan errors if the cause had a known kind.
+//
+// Kinds added with RegisterKind must be registered in this process too,
+// or the error will be an Internal error. If data can't be decoded, an
+// InvalidInput error saying so is returned.
+func FromJSON(data []byte) error {
+	var decoded jsonError
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return InvalidInput("Unable to decode error JSON", err)
+	}
+
+	// Rebuild the chain from the innermost cause outwards.
+	var wrapped error
+	for i := len(decoded.Causes) - 1; i >= 0; i-- {
+		wrapped = fromCauseFields(decoded.Causes[i], wrapped)
+	}
+
+	kind, ok := LookupKind(decoded.Kind)
+	if !ok {
+		kind = InternalKind
+	}
+	if wrapped == nil {
+		return newError(kind, decoded.Message, decoded.Fields)
+	}
+	return newError(kind, decoded.Message, decoded.Fields, wrapped)
+}
+
+// fromCauseFields rebuilds one of the causes in a decoded error.
+func fromCauseFields(cause Fields, wrapped error) error {
+	message, _ := cause[MessageKey].(string)
+	kindName, _ := cause[KindKey].(string)
+	kind, ok := LookupKind(kindName)
+	if !ok {
+		return &decodedError{message: message, wrapped: wrapped}
+	}
+	fields := Fields{}
+	for k, v := range cause {
+		if k != KindKey && k != MessageKey {
+			fields[k] = v
+		}
+	}
+	if wrapped == nil {
+		return newError(kind, message, fields)
+	}
+	return newError(kind, message, fields, wrapped)
+}
+
+// decodedError stands in for an error that wasn't a khan error when it
+// was encoded, so all we know about it is its message.
+type decodedError struct {
+	message string
+	wrapped error
+}
+
+func (e *decodedError) Error() string {
+	return e.message
+}
+
+func (e *decodedError) Unwrap() error {
+	return e.wrapped
+}
+
+// jsonSafeFields returns a copy of fields where the values that can't be
+// encoded as JSON have been replaced by their StringifyField form.
+func jsonSafeFields(fields Fields) Fields {
+	if len(fields) == 0 {
+		return nil
+	}
+	safe := make(Fields, len(fields))
+	for k, v := range fields {
+		safe[k] = jsonSafeField(v)
+	}
+	return safe
+}
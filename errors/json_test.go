@@ -0,0 +1,72 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+func (es *errorSuite) TestMarshalJSON() {
+	inner := errors.NotFound("No such user", errors.Fields{"kaid": "123"})
+	e := errors.Internal("Unable to load", inner,
+		errors.Fields{"attempt": 2, "ch": make(chan int)})
+
+	data, err := json.Marshal(e)
+	es.Require().NoError(err)
+	es.Require().JSONEq(`{
+		"kind": "internal error",
+		"message": "Unable to load",
+		"fields": {
+			"attempt": 2,
+			"kaid": "123",
+			"ch": "`+errors.StringifyField(errors.GetFields(e)["ch"])+`"
+		},
+		"causes": [{"Kind": "not found", "Message": "No such user", "kaid": "123"}]
+	}`, string(data))
+}
+
+func (es *errorSuite) TestFromJSON() {
+	inner := errors.NotFound("No such user", errors.Fields{"kaid": "123"})
+	e := errors.Unauthorized("Unable to load", inner, errors.Fields{"attempt": 2})
+	data, err := json.Marshal(e)
+	es.Require().NoError(err)
+
+	e2 := errors.FromJSON(data)
+	es.Require().Equal(errors.UnauthorizedKind, errors.GetKind(e2))
+	es.Require().True(errors.Is(e2, errors.UnauthorizedKind))
+	es.Require().True(errors.Is(e2, errors.NotFoundKind))
+	es.Require().Equal(errors.Fields{
+		"Kind":    "unauthorized error",
+		"Message": "Unable to load",
+		"attempt": 2.0,
+		"kaid":    "123",
+	}, errors.GetFields(e2))
+	es.Require().Equal(
+		[]errors.Fields{{"Kind": "not found", "Message": "No such user", "kaid": "123"}},
+		errors.GetWrappedErrors(e2))
+
+	// Encoding the rebuilt error gives the same JSON again.
+	data2, err := json.Marshal(e2)
+	es.Require().NoError(err)
+	es.Require().JSONEq(string(data), string(data2))
+}
+
+func (es *errorSuite) TestFromJSONNonKhanCause() {
+	e := errors.Service(fmt.Errorf("connection reset"))
+	data, err := json.Marshal(e)
+	es.Require().NoError(err)
+
+	e2 := errors.FromJSON(data)
+	es.Require().Equal(errors.ServiceKind, errors.GetKind(e2))
+	es.Require().Equal("connection reset", errors.GetFields(e2)[errors.MessageKey])
+	es.Require().Equal("connection reset", errors.Unwrap(e2).Error())
+}
+
+func (es *errorSuite) TestFromJSONInvalid() {
+	e := errors.FromJSON([]byte(`{"kind": "no such kind"}`))
+	es.Require().Equal(errors.InternalKind, errors.GetKind(e))
+
+	e = errors.FromJSON([]byte(`not json`))
+	es.Require().Equal(errors.InvalidInputKind, errors.GetKind(e))
+}
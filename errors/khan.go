@@ -1,37 +1,56 @@
 package errors
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"sort"
+	"runtime"
+	"strings"
 
 	simpler "github.com/StevenACoffman/simplerr/errors"
 )
 
-// khanError is our error implementation. `source` is a string that
+// Error is our error implementation, and the type of the errors returned by
+// NotFound, Internal and the other constructors. Use As to get at it, and
+// its methods to inspect it:
+//
+//	var khanErr *errors.Error
+//	if errors.As(err, &khanErr) {
+//	    log.Print(khanErr.Kind(), khanErr.Message(), khanErr.Fields())
+//	}
+//
+// `source` is a string that
 // uniquely identifies the error source, such as "package.function". `kind`
 // is an error category. `message` is an error message that will appear in
 // the logs. `wrappedErr` is an optional wrapped error. `origin` is a
 // string in the format "<filename>:<linenumber>". `extra` is an optional
-// collection of key value pairs to log when logging the error.
-type khanError struct {
+// collection of key value pairs to log when logging the error. `fields`
+// is the flattened fields of the error and those it wraps, before
+// redaction. `flat` is the simplerr error holding the redacted flattened
+// fields and the stack trace. `severity` is the Severity the error was
+// created with, if any, and `public` is its Public message.
+type Error struct {
+	source     string
 	message    string
+	public     string
 	kind       errorKind
+	severity   Severity
 	wrappedErr error
 	extra      Fields
+	fields     Fields
+	flat       error
 }
 
-func (e *khanError) wrappedErrors() []Fields {
+func (e *Error) wrappedErrors() []Fields {
 	if e == nil || e.wrappedErr == nil {
 		return []Fields{}
 	}
-	// inner, ok := e.wrappedErr.(*khanError)
-	innerFields := simpler.GetFields(e.wrappedErr)
+	// inner, ok := e.wrappedErr.(*Error)
+	innerFields := rawFields(e.wrappedErr)
 	if len(innerFields) != 0 {
 		return []Fields{Fields(innerFields)}
 	}
 	// unlikely to get past this but should work fine regardless
-	var inner *khanError
+	var inner *Error
 	ok := As(e.wrappedErr, &inner)
 	var wrapped []Fields
 	if !ok {
@@ -45,65 +64,21 @@ func (e *khanError) wrappedErrors() []Fields {
 }
 
 // Error returns a short error message. It constitutes the "error" interface.
-// We expose all metadata (except some special empty fields) about the error
-// here to ensure that when errors are sent to the requestlogs that all the
-// data is captured. The error data is also exposed in a structured form in
-// stackdriver logs using LogFields.
-func (e *khanError) Error() string {
-	if e == nil {
+// We expose all the fields of the error here, in the simplerr format, to
+// ensure that when errors are sent to the requestlogs that all the data is
+// captured. The error data is also exposed in a structured form through
+// LogValue.
+func (e *Error) Error() string {
+	if e == nil || e.flat == nil {
 		return ""
 	}
-
-	var buf bytes.Buffer
-
-	// TODO(csilvers): do we want to do something special if the
-	// wrapped error is a sentinel?  Like maybe show our error text
-	// above the sentinel, followed by a special "Wraps sentinel:"
-	// line.  (We can tell if we're a sentinel based on `source`
-	// having `"init"`.)
-	// TODO(csilvers): similarly for non-khan errors: we may want to
-	// show the first khan-error instead, that wraps the non-khan error.
-	if e.wrappedErr != nil {
-		buf.WriteString(e.wrappedErr.Error())
-		buf.WriteString("\nWrapped by: ")
-	}
-
-	_, _ = fmt.Fprintf(&buf, "%s", string(getKind(e)))
-	if e.message != "" {
-		_, _ = fmt.Fprintf(&buf, " %s", e.message)
-	}
-	if e.extra != nil {
-		keys := make([]string, len(e.extra))
-		i := 0
-		for k := range e.extra {
-			keys[i] = k
-			i++
-		}
-		sort.Strings(keys)
-		for _, k := range keys {
-			fieldValue := StringifyField(e.extra[k])
-			// Ignore empty fields for special keys. These keys are set by the
-			// graphql error handler with empty values to ensure that the
-			// fields are present in the log schema and thus avoid log export
-			// problems. But if they are empty we don't need to see them in the
-			// message.
-			if fieldValue == "" && (k == "handledGraphQLPanic" ||
-				k == "panicErr.Kind" ||
-				k == "panicErr.Message" ||
-				k == "panicErr.Source" ||
-				k == "panicValue") {
-				continue
-			}
-			_, _ = fmt.Fprintf(&buf, ", %s = %s", k, fieldValue)
-		}
-	}
-	return buf.String()
+	return e.flat.Error()
 }
 
 // Unwrap returns the wrapped error, if any. This function allows use of
 // errors.Unwrap, errors.Is, and errors.As.
-func (e *khanError) Unwrap() error {
-	return e.wrappedErr
+func (e *Error) Unwrap() error {
+	return e.Cause()
 }
 
 // Is implements the test that errors.Is uses to decide if two errors are
@@ -112,44 +87,161 @@ func (e *khanError) Unwrap() error {
 // special cases here. The special case we support is matching with kinds.
 // An error is "equal" to it's kind. This let's us use errors.Is find out
 // which kind a khan error is.
-func (e *khanError) Is(target error) bool {
+func (e *Error) Is(target error) bool {
 	return e.kind == target
 }
 
+// As sets target to the kind of the error, if target is a kind. Other
+// targets are handled by errors.As itself.
+func (e *Error) As(target any) bool {
+	if kind, ok := target.(*errorKind); ok {
+		*kind = e.kind
+		return true
+	}
+	return false
+}
+
+// Kind returns the kind of the error, like GetKind does.
+func (e *Error) Kind() errorKind {
+	return getKind(e)
+}
+
+// Message returns the message that the error was created with, which is
+// "" if it was only given an error to wrap.
+func (e *Error) Message() string {
+	if e == nil {
+		return ""
+	}
+	return e.message
+}
+
+// Fields returns a copy of the fields of this error alone, including
+// those added from a context, but not those of the errors it wraps, with
+// sensitive values redacted. Use GetFields for the fields of the whole
+// chain.
+func (e *Error) Fields() Fields {
+	if e == nil {
+		return Fields{}
+	}
+	return redactFields(e.extra)
+}
+
+// Cause returns the error that this error wraps, or nil if there isn't
+// one. It is the same as Unwrap, and is there for compatibility with
+// github.com/pkg/errors.
+func (e *Error) Cause() error {
+	if e == nil || e.wrappedErr == e.kind {
+		return nil
+	}
+	return e.wrappedErr
+}
+
+// StackTrace returns the frames of the stack trace recorded when the error
+// was created, from the innermost call outwards. GetStackTrace returns the
+// innermost stack trace of the whole chain instead.
+func (e *Error) StackTrace() []runtime.Frame {
+	if e == nil {
+		return nil
+	}
+	return stackFrames(e.flat)
+}
+
 const (
 	MessageKey        = "Message"
 	KindKey           = "Kind"
+	SourceKey         = "Source"
 	BadArgsKey        = "badargs"
 	InvalidErrArgsKey = "Invalid error arguments"
+	ExtraMessagesKey  = "Extra messages"
 )
 
+// keepAllArgs is the type of KeepAll.
+type keepAllArgs bool
+
+// KeepAll is an error constructor argument that makes the constructor
+// keep every error, message and Fields it is passed, rather than only the
+// last of each. The errors are combined with Join, the Fields are merged
+// with later ones winning, and messages after the first are kept in the
+// ExtraMessagesKey field:
+//
+//	errors.Internal(errors.KeepAll, "Sync failed", fetchErr, saveErr)
+const KeepAll keepAllArgs = true
+
 func newError(kind errorKind, args ...any) error {
-	e := &khanError{kind: kind}
+	e := &Error{kind: kind}
+	var (
+		errs     []error
+		messages []string
+		extras   []Fields
+		ctxs     []context.Context
+		typed    []Field
+		keepAll  bool
+	)
 	badArgs := make([]any, 0)
 	for _, arg := range args {
 		switch v := arg.(type) {
+		case keepAllArgs:
+			keepAll = bool(v)
 		case error:
-			e.wrappedErr = v
+			errs = append(errs, v)
 		case string:
-			e.message = v
+			messages = append(messages, v)
+		case Source:
+			if e.source != "" {
+				// There's no way to keep more than one source.
+				badArgs = append(badArgs, Source(e.source))
+			}
+			e.source = string(v)
+		case Severity:
+			if e.severity != 0 {
+				// Nor more than one severity.
+				badArgs = append(badArgs, e.severity)
+			}
+			e.severity = v
+		case Public:
+			if e.public != "" {
+				// Nor more than one public message.
+				badArgs = append(badArgs, Public(e.public))
+			}
+			e.public = string(v)
 		case Fields:
-			e.extra = v
+			extras = append(extras, v)
 		case map[string]any:
-			e.extra = v
+			extras = append(extras, v)
+		case context.Context:
+			ctxs = append(ctxs, v)
+		case Field:
+			typed = append(typed, v)
 		default:
 			badArgs = append(badArgs, v)
 		}
 	}
+	if keepAll {
+		e.keepAll(errs, messages, extras)
+	} else {
+		badArgs = append(badArgs, e.keepLast(errs, messages, extras)...)
+		if n := len(ctxs); n > 1 {
+			for _, ctx := range ctxs[:n-1] {
+				badArgs = append(badArgs, ctx)
+			}
+			ctxs = ctxs[n-1:]
+		}
+	}
+	e.addTypedFields(typed)
+	e.addContextFields(ctxs)
 	if len(badArgs) > 0 {
 		e.message = "Invalid error constructor argument(s): " + e.message
 		details := make([]string, len(badArgs))
 		for i, arg := range badArgs {
-			details[i] = fmt.Sprintf("%#v", arg)
+			details[i] = describeBadArg(arg)
 		}
-		if e.extra == nil {
-			e.extra = Fields{}
+		// Copy the fields, so we don't modify the caller's map.
+		extra := Fields{}
+		for k, v := range e.extra {
+			extra[k] = v
 		}
-		e.extra[InvalidErrArgsKey] = details
+		extra[InvalidErrArgsKey] = details
+		e.extra = extra
 	}
 
 	fields := Fields{
@@ -157,7 +249,7 @@ func newError(kind errorKind, args ...any) error {
 	}
 	for _, f := range e.wrappedErrors() {
 		for s, a := range f {
-			if _, ok := fields[s]; !ok && s != "Source" && s != "Origin" {
+			if _, ok := fields[s]; !ok && s != "Origin" {
 				fields[s] = a
 			}
 		}
@@ -167,6 +259,9 @@ func newError(kind errorKind, args ...any) error {
 			fields[k] = v
 		}
 	}
+	if e.source != "" {
+		fields[SourceKey] = e.source
+	}
 
 	if e.message != "" {
 		if len(fields) == 0 {
@@ -175,13 +270,106 @@ func newError(kind errorKind, args ...any) error {
 			fields[MessageKey] = e.message
 		}
 	}
+	e.fields = fields
 	// if no other wrapped error, use kind
 	if e.wrappedErr == nil || e.wrappedErr == kind {
-		return simpler.WrapWithFieldsAndDepth(kind, simpler.Fields(fields), 2)
+		e.flat = simpler.WrapWithFieldsAndDepth(kind, simpler.Fields(redactFields(fields)), 2)
+		return e
 	}
 	// we double wrap to ensure errors.Is true for both kind and original
 	tmpErr := simpler.With(e.wrappedErr, kind)
-	return simpler.WrapWithFieldsAndDepth(tmpErr, simpler.Fields(fields), 2)
+	e.flat = simpler.WrapWithFieldsAndDepth(tmpErr, simpler.Fields(redactFields(fields)), 2)
+	return e
+}
+
+// keepLast uses the last error, message and Fields passed to a
+// constructor, and returns the ones before them so they can be reported
+// as invalid arguments.
+func (e *Error) keepLast(errs []error, messages []string, extras []Fields) []any {
+	var dropped []any
+	if n := len(errs); n > 0 {
+		e.wrappedErr = errs[n-1]
+		for _, err := range errs[:n-1] {
+			dropped = append(dropped, err)
+		}
+	}
+	if n := len(messages); n > 0 {
+		e.message = messages[n-1]
+		for _, message := range messages[:n-1] {
+			dropped = append(dropped, message)
+		}
+	}
+	if n := len(extras); n > 0 {
+		e.extra = extras[n-1]
+		for _, extra := range extras[:n-1] {
+			dropped = append(dropped, extra)
+		}
+	}
+	return dropped
+}
+
+// keepAll uses every error, message and Fields passed to a constructor:
+// the errors are joined, the Fields are merged with later ones winning,
+// and the messages after the first are kept under ExtraMessagesKey.
+func (e *Error) keepAll(errs []error, messages []string, extras []Fields) {
+	switch len(errs) {
+	case 0:
+	case 1:
+		e.wrappedErr = errs[0]
+	default:
+		e.wrappedErr = Join(errs...)
+	}
+	if len(messages) > 0 {
+		e.message = messages[0]
+	}
+	if len(extras) == 0 && len(messages) < 2 {
+		return
+	}
+	e.extra = Fields{}
+	for _, extra := range extras {
+		for k, v := range extra {
+			e.extra[k] = v
+		}
+	}
+	if len(messages) > 1 {
+		e.extra[ExtraMessagesKey] = messages[1:]
+	}
+}
+
+// addTypedFields adds the fields made with FieldKey.Of to the error's own
+// fields, winning over those in Fields, with later ones winning.
+func (e *Error) addTypedFields(typed []Field) {
+	if len(typed) == 0 {
+		return
+	}
+	// Copy the fields, so we don't modify the caller's map.
+	extra := Fields{}
+	for k, v := range e.extra {
+		extra[k] = v
+	}
+	for _, field := range typed {
+		extra[field.Key] = field.Value
+	}
+	e.extra = extra
+}
+
+// addContextFields adds the fields that were added to ctxs with WithFields
+// to the error's own fields. The error's Fields win over the context's, and
+// later contexts win over earlier ones.
+func (e *Error) addContextFields(ctxs []context.Context) {
+	extra := Fields{}
+	for _, ctx := range ctxs {
+		for k, v := range FieldsFromContext(ctx) {
+			extra[k] = v
+		}
+	}
+	if len(extra) == 0 {
+		return
+	}
+	for k, v := range e.extra {
+		extra[k] = v
+	}
+	e.extra = extra
 }
 
 // Fail if Wrap() has the wrong args.  All the errors here are
@@ -191,14 +379,14 @@ func _fail(args ...any) error {
 	return Internal(args...)
 }
 
-// Wrap takes a khanError as input and some new field key/value pairs,
+// Wrap takes a khan error as input and some new field key/value pairs,
 // and returns a new error that has the same "kind" as the existing
 // error, plus the specified key/value pairs.  For convenience, rather
 // than using errors.Fields{} to specify the key/value pairs, they
 // are specified as alternating string/any objects.
 // Also for convenience, if nil is passed in then nil is returned.
 //
-// If there is an error in wrapping -- the input is not a khanError,
+// If there is an error in wrapping -- the input is not a khan error,
 // a non-string key is specified -- then the wrapped error is actually
 // an error.Internal() that indicates the problem with wrapping.
 // .
@@ -213,7 +401,8 @@ func Wrap(err error, args ...any) error {
 			err, Fields{BadArgsKey: args})
 	}
 
-	fields := GetFields(err)
+	// Use the raw fields, so the new error can still reveal them.
+	fields := rawFields(err)
 	for i := 0; i < len(args); i += 2 {
 		key, ok := args[i].(string)
 		if !ok {
@@ -223,56 +412,104 @@ func Wrap(err error, args ...any) error {
 		fields[key] = args[i+1]
 	}
 	// if err is kind without any wrapping
-	khanKind, ok := err.(errorKind)
-	if ok {
+	if khanKind, ok := err.(errorKind); ok {
 		return newError(khanKind, fields)
 	}
-	// if err is wrapped kind, it's a khan error
-	if As(err, &khanKind) {
-		if khanKind.IsValidKind() {
-			return newError(khanKind, err, fields)
-		}
-	}
-
-	// khanErr, ok := err.(*khanError)
-	var khanErr *khanError
-	ok = As(err, &khanErr)
-	if !ok {
+	if !IsKhanError(err) {
 		// "Internal" is the best default, but not always right.
 		// e.g. for client.GCS() errors, "Service" would be better.
-		// The solution is to change our GCS wrapper to return khanErrors,
+		// The solution is to change our GCS wrapper to return khan errors,
 		// like we do for our Datastore wrapper.
 		return Internal(err, fields)
 	}
-	errKind := getKind(khanErr)
+	// err is, or wraps, a khan error or a Join of them.
+	errKind := GetKind(err)
 	if errKind == UnspecifiedKind {
 		// This probably can't happen, but just in case...
 		return _fail("Cannot determine kind of error-to-wrap", err)
 	}
-	return newError(errKind, khanErr, fields)
+	return newError(errKind, err, fields)
 }
 
-//
-// func (ke *khanError) MarshalLogObject(enc zapcore.ObjectEncoder) error {
-//	enc.AddString("kind", string(ke.kind))
-//	enc.AddString("message", ke.Error())
-//	enc.AddString("stacktrace", fmt.Sprintf("%+v", ke.StackTrace()))
-//	err := enc.AddReflected("fields", ke.fields)
-//	if err != nil {
-//		return errors.Wrapf(err, "Unable to AddReflected fields to log: %+v", ke.fields)
-//	}
-//	err = enc.AddReflected("cause", ke.cause)
-//	if err != nil {
-//		return errors.Wrapf(err, "Unable to AddReflected cause to log %+v", ke.cause)
-//	}
-//
-//	return nil
-//}
-
+// GetFields returns the fields of err and of every error it wraps, along
+// with the Kind and Message of err. Sensitive values are redacted; see
+// RegisterSensitiveKeys and Secret.
 func GetFields(err error) Fields {
+	return redactFields(rawFields(err))
+}
+
+// rawFields is GetFields without redaction. Values marked with Secret are
+// left as they are, so that they are still known to be secret if the
+// fields end up in another error.
+func rawFields(err error) Fields {
+	for c := err; c != nil; c = Unwrap(c) {
+		switch e := c.(type) {
+		case *Error:
+			fields := make(Fields, len(e.fields))
+			for k, v := range e.fields {
+				fields[k] = v
+			}
+			return fields
+		case *joinError:
+			return e.fields()
+		}
+	}
 	return Fields(simpler.GetFields(err))
 }
 
+// GetWrappedErrors returns the fields of the errors that err wraps, which
+// is handy for structured logging. Khan errors are flattened into a
+// single Fields, while other errors only have a Message. It returns nil
+// if err isn't a khan error.
+func GetWrappedErrors(err error) []Fields {
+	var khanErr *Error
+	if !As(err, &khanErr) {
+		return nil
+	}
+	wrapped := khanErr.wrappedErrors()
+	for i, fields := range wrapped {
+		wrapped[i] = redactFields(fields)
+	}
+	return wrapped
+}
+
+// GetSource returns the source location of the error, as "package.function".
+// This is the errors.Source() passed to the constructor of the error or of
+// any error it wraps, with the outermost one winning. If no Source was
+// given, it is the function that created the innermost error with a
+// stack trace. If that can't be determined either, it returns "".
+func GetSource(err error) Source {
+	if source, ok := GetFields(err)[SourceKey].(string); ok {
+		return Source(source)
+	}
+	for _, frame := range GetStackTrace(err) {
+		if frame.Function != "" {
+			// Trim the import path, so "github.com/a/b/pkg.Func" is "pkg.Func".
+			return Source(frame.Function[strings.LastIndex(frame.Function, "/")+1:])
+		}
+	}
+	return ""
+}
+
+// GetStackTrace returns the frames of the innermost stack trace in the err
+// chain, which is the one recorded closest to where the error happened.
+// The frames are ordered from the innermost call outwards.
+func GetStackTrace(err error) []runtime.Frame {
+	var origin *simpler.StackTrace
+	for c := err; c != nil; c = Unwrap(c) {
+		if khanErr, ok := c.(*Error); ok {
+			c = khanErr.flat
+		}
+		if st, ok := c.(stackTracer); ok {
+			origin = st.StackTrace()
+		}
+	}
+	if origin == nil {
+		return nil
+	}
+	return traceFrames(origin)
+}
+
 // IsKhanError returns true if the error is a khan error. Note we don't
 // check wrapped errors - this is a check of the outer error only. This
 // check isn't like errors.As which is used to get access to error details
@@ -299,3 +536,12 @@ func StringifyField(value any) string {
 
 // Fields is re-exported here to avoid leaking direct import implementation details
 type Fields simpler.Fields
+
+// Source is an error constructor argument that overrides the default
+// source location of the error. By convention it is "package.function":
+//
+//	errors.NotFound("No such user", errors.Source("users.GetUser"))
+//
+// It is kept in the error's fields under SourceKey, so it is preserved
+// when the error is wrapped. Use GetSource to read it back.
+type Source string
@@ -1,6 +1,8 @@
 package errors_test
 
 import (
+	"context"
+	stderrors "errors"
 	"fmt"
 	"testing"
 
@@ -41,10 +43,48 @@ func (es *errorSuite) TestWrappedError() {
 	es.Require().Equal(
 		"Fields: [Kind:internal error,Message:This is not OK], Cause: internal error: This is not OK",
 		e.Error())
-	// non-kind, non-khan errors are wrapped twice
-	// inner wrapper front is kind, back is non-khan, non-kind root
-	// then withstackandfields holds the khan err
-	es.Require().Equal(innerError, errors.Unwrap(errors.Unwrap(e)))
+	// Unwrap gives the wrapped error itself, like Cause does.
+	es.Require().Equal(innerError, errors.Unwrap(e))
+	es.Require().Equal(innerError, stderrors.Unwrap(e))
+	es.Require().NoError(errors.Unwrap(errors.Internal("no cause")))
+}
+
+func (es *errorSuite) TestErrorType() {
+	ctx := errors.WithFields(context.Background(), errors.Fields{"requestID": "abc"})
+	inner := errors.NotFound("No such user", errors.Fields{"kaid": "123"})
+	e := fmt.Errorf("loading: %w",
+		errors.Internal(ctx, "Unable to load", inner, errors.Fields{"attempt": 2}))
+
+	var khanErr *errors.Error
+	es.Require().True(errors.As(e, &khanErr))
+	es.Require().Equal(errors.InternalKind, khanErr.Kind())
+	es.Require().Equal("Unable to load", khanErr.Message())
+	es.Require().Equal(errors.Fields{"attempt": 2, "requestID": "abc"}, khanErr.Fields())
+	es.Require().Equal(inner, khanErr.Cause())
+	es.Require().NotEmpty(khanErr.StackTrace())
+	es.Require().Equal(
+		"github.com/StevenACoffman/khanerr/errors_test.(*errorSuite).TestErrorType",
+		khanErr.StackTrace()[0].Function)
+	frames := khanErr.StackTrace()
+	es.Require().Equal("runtime.goexit", frames[len(frames)-1].Function)
+
+	// The stdlib As works too, and finds the outermost khan error.
+	khanErr = nil
+	es.Require().True(stderrors.As(e, &khanErr))
+	es.Require().Equal("Unable to load", khanErr.Message())
+
+	// Changing the returned Fields doesn't change the error.
+	khanErr.Fields()["attempt"] = 3
+	es.Require().Equal(2, khanErr.Fields()["attempt"])
+
+	var innerErr *errors.Error
+	es.Require().True(errors.As(khanErr.Cause(), &innerErr))
+	es.Require().Equal(errors.NotFoundKind, innerErr.Kind())
+	es.Require().NoError(innerErr.Cause())
+
+	// Wrap keeps the kind of a khan error, even behind another wrapper.
+	es.Require().Equal(errors.InternalKind, errors.GetKind(errors.Wrap(e, "a", 1)))
+	es.Require().True(errors.Is(errors.Wrap(e, "a", 1), inner))
 }
 
 func (es *errorSuite) TestNew() {
@@ -64,6 +104,50 @@ func (es *errorSuite) TestInvalidParameter() {
 	es.Require().Equal("Invalid error constructor argument(s): Message", fields["Message"])
 }
 
+func (es *errorSuite) TestDuplicateParameters() {
+	first := fmt.Errorf("first")
+	second := fmt.Errorf("second")
+	extra := errors.Fields{"a": 1}
+	e := errors.Internal("one", first, extra, "two", second, errors.Fields{"b": 2},
+		errors.Source("x.Y"), errors.Source("x.Z"))
+	fields := errors.GetFields(e)
+	es.Require().Equal(
+		[]string{`"x.Y"`, `error("first")`, `"one"`, `errors.Fields{"a":1}`},
+		fields[errors.InvalidErrArgsKey])
+	es.Require().Equal("Invalid error constructor argument(s): two", fields[errors.MessageKey])
+	es.Require().Equal(2, fields["b"])
+	es.Require().NotContains(fields, "a")
+	es.Require().Equal("x.Z", fields[errors.SourceKey])
+	es.Require().True(errors.Is(e, second))
+	es.Require().False(errors.Is(e, first))
+	// The caller's Fields are left alone.
+	es.Require().Equal(errors.Fields{"a": 1}, extra)
+}
+
+func (es *errorSuite) TestKeepAll() {
+	first := errors.NotFound("No such user")
+	second := fmt.Errorf("second")
+	extra := errors.Fields{"a": 1, "b": 1}
+	e := errors.Internal(errors.KeepAll, "one", first, extra, "two", second,
+		errors.Fields{"b": 2}, "three")
+	fields := errors.GetFields(e)
+	es.Require().NotContains(fields, errors.InvalidErrArgsKey)
+	es.Require().Equal("one", fields[errors.MessageKey])
+	es.Require().Equal([]string{"two", "three"}, fields[errors.ExtraMessagesKey])
+	es.Require().Equal(1, fields["a"])
+	es.Require().Equal(2, fields["b"])
+	es.Require().Equal(errors.InternalKind, errors.GetKind(e))
+	es.Require().True(errors.Is(e, first))
+	es.Require().True(errors.Is(e, second))
+	es.Require().True(errors.Is(e, errors.NotFoundKind))
+	es.Require().Equal(errors.Fields{"a": 1, "b": 1}, extra)
+
+	// With a single one of each, KeepAll makes no difference.
+	es.Require().Equal(
+		errors.GetFields(errors.NotFound("one", first, extra)),
+		errors.GetFields(errors.NotFound(errors.KeepAll, "one", first, extra)))
+}
+
 func (es *errorSuite) TestKind() {
 	inner := fmt.Errorf("more inner")
 	es.Require().Equal(errors.UnspecifiedKind, errors.GetKind(inner))
@@ -178,6 +262,39 @@ func (es *errorSuite) TestWrapInDev() {
 	es.Require().Equal(nil, e2)
 }
 
+func (es *errorSuite) TestSource() {
+	e := errors.NotFound("No such user", errors.Source("users.GetUser"))
+	es.Require().Equal(errors.Source("users.GetUser"), errors.GetSource(e))
+	es.Require().Equal("No such user", errors.GetFields(e)[errors.MessageKey])
+	es.Require().NotContains(errors.GetFields(e), errors.InvalidErrArgsKey)
+
+	// The source is kept when wrapping, unless the wrapper overrides it.
+	es.Require().Equal(errors.Source("users.GetUser"), errors.GetSource(errors.Internal(e)))
+	es.Require().Equal(errors.Source("users.GetUser"), errors.GetSource(errors.Wrap(e, "a", 1)))
+	es.Require().Equal(
+		errors.Source("users.List"),
+		errors.GetSource(errors.Internal(e, errors.Source("users.List"))))
+
+	// Without an explicit source, it is the function that created the error.
+	es.Require().Equal(
+		errors.Source("errors_test.(*errorSuite).TestSource"),
+		errors.GetSource(errors.Internal("no source")))
+	es.Require().Equal(errors.Source(""), errors.GetSource(fmt.Errorf("not khan")))
+}
+
+func (es *errorSuite) TestGetStackTrace() {
+	es.Require().Nil(errors.GetStackTrace(nil))
+	es.Require().Nil(errors.GetStackTrace(fmt.Errorf("plain")))
+
+	// The trace goes all the way out, to the call that started the
+	// goroutine.
+	frames := errors.GetStackTrace(fmt.Errorf("loading: %w", errors.NotFound()))
+	es.Require().Equal(
+		"github.com/StevenACoffman/khanerr/errors_test.(*errorSuite).TestGetStackTrace",
+		frames[0].Function)
+	es.Require().Equal("runtime.goexit", frames[len(frames)-1].Function)
+}
+
 func (es *errorSuite) TestStringifyField() {
 	es.Require().Equal("42", errors.StringifyField(42))
 	es.Require().Equal("a", errors.StringifyField("a"))
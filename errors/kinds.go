@@ -1,5 +1,14 @@
 package errors
 
+import (
+	"maps"
+	"sync"
+)
+
+// The core kinds, their constructors and their HTTP statuses and other
+// properties are generated from kinds/kinds.yaml.
+//go:generate go run ../internal/kindgen -defs ../kinds/kinds.yaml -go kinds_gen.go -grpc ../grpcerr/kinds_gen.go -gql ../gqlerr/kinds_gen.go -ts ../kinds/kinds.ts -proto ../kinds/kinds.proto
+
 // errorKind is an error category like an exception class in Python. It's
 // used to differentiate between different types of errors that a function
 // can return when handling an error. It also is used when analyzing logs
@@ -8,9 +17,8 @@ package errors
 // kinds defined, say 10-20 max. They should be general enough to be useful
 // in many different contexts.
 //
-// Right now there isn't support for packages to define their own error kinds,
-// since the function to create an error is private. In the future we should
-// consider whether to allow this.
+// Packages can define their own error kinds with RegisterKind or NewKind,
+// but please check whether one of the core kinds fits before adding one.
 type errorKind string
 
 // Error is a function that makes errorKind implement the error interface. This
@@ -20,161 +28,137 @@ func (e errorKind) Error() string {
 	return string(e)
 }
 
-// NotFound creates an error of kind NotFoundKind.  args can be
-// (1) an error to wrap
-// (2) a string to use as the error message
-// (3) an errors.Fields{} object of key/value pairs to associate with the error
-// (4) an errors.Source("source-location") to override the default source-loc
-// If you specify any of these multiple times, only the last one wins.
-func NotFound(args ...any) error {
-	return newError(NotFoundKind, args...)
+// String presents the value of the string, like "Not Found"
+// The fmt package (and many others) look for this to print values.
+func (e errorKind) String() string {
+	return string(e)
 }
 
-// InvalidInput creates an error of kind InvalidKind.
-func InvalidInput(args ...any) error {
-	return newError(InvalidInputKind, args...)
+// IsValidKind returns true if e is one of the core kinds declared above or
+// an application-defined kind added with RegisterKind.
+func (e errorKind) IsValidKind() bool {
+	return e.isCoreKind() || kinds.isRegistered(e)
 }
 
-// NotAllowed creates an error of kind NotAllowedKind.
-func NotAllowed(args ...any) error {
-	return newError(NotAllowedKind, args...)
+// New creates an error of kind e, taking the same arguments as NotFound,
+// Internal and the other constructors. It is mostly useful for kinds
+// added with RegisterKind, which have no constructor of their own.
+func (e errorKind) New(args ...any) error {
+	return newError(e, args...)
 }
 
-// Unauthorized creates an error of kind UnauthorizedKind.
-func Unauthorized(args ...any) error {
-	return newError(UnauthorizedKind, args...)
+// kindRegistry holds the application-defined error kinds. The core kinds
+// are never stored here; IsValidKind checks for them first.
+type kindRegistry struct {
+	mu    sync.RWMutex
+	kinds map[errorKind]struct{}
 }
 
-// Internal creates an error of kind InternalKind.
-func Internal(args ...any) error {
-	return newError(InternalKind, args...)
-}
+var kinds = &kindRegistry{kinds: map[errorKind]struct{}{}}
 
-// GraphqlResponse creates an error of kind GraphqlResponseKind.
-func GraphqlResponse(args ...any) error {
-	return newError(GraphqlResponseKind, args...)
+func (r *kindRegistry) isRegistered(kind errorKind) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.kinds[kind]
+	return ok
 }
 
-// NotImplemented creates an error of kind NotImplementedKind.
-func NotImplemented(args ...any) error {
-	return newError(NotImplementedKind, args...)
+func (r *kindRegistry) register(kind errorKind) error {
+	if kind == "" {
+		return InvalidInput("Cannot register an empty error kind")
+	}
+	if kind.isCoreKind() {
+		return NotAllowed("Error kind conflicts with a core error kind",
+			Fields{KindKey: string(kind)})
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.kinds[kind]; ok {
+		return NotAllowed("Error kind is already registered",
+			Fields{KindKey: string(kind)})
+	}
+	r.kinds[kind] = struct{}{}
+	return nil
 }
 
-// TransientKhanService creates an error of kind TransientKhanServiceKind.
-func TransientKhanService(args ...any) error {
-	return newError(TransientKhanServiceKind, args...)
+// kindTable is a concurrency-safe map from error kinds to some property
+// of the kind, such as its HTTP status. It starts out with the values for
+// the core kinds, and values for registered kinds are added with set.
+type kindTable[V any] struct {
+	mu     sync.RWMutex
+	values map[errorKind]V
 }
 
-// KhanService creates an error of kind KhanServiceKind.
-func KhanService(args ...any) error {
-	return newError(KhanServiceKind, args...)
+// newKindTable returns a table that starts out with a copy of values, so
+// that set never changes the generated tables of the core kinds.
+func newKindTable[V any](values map[errorKind]V) *kindTable[V] {
+	return &kindTable[V]{values: maps.Clone(values)}
 }
 
-// Service creates an error of kind ServiceKind.
-func Service(args ...any) error {
-	return newError(ServiceKind, args...)
+func (t *kindTable[V]) get(kind errorKind) (V, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	v, ok := t.values[kind]
+	return v, ok
 }
 
-// TransientService creates an error of kind TransientServiceKind.
-func TransientService(args ...any) error {
-	return newError(TransientServiceKind, args...)
+func (t *kindTable[V]) set(kind errorKind, v V) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.values[kind] = v
 }
 
-// sync-start:error-kinds 1222935478 services/static/javascript/logging/internal/types.js
-const (
-	// NotFoundKind means that some requested resource wasn't found. If the
-	// resource couldn't be retrieved due to access control use
-	// UnauthorizedKind instead. If the resource couldn't be found because
-	// the input was invalid use InvalidInputKind instead.
-	NotFoundKind errorKind = "not found"
-
-	// InvalidInputKind means that there was a problem with the provided input.
-	// This kind indicates inputs that are problematic regardless of the state
-	// of the system. Use NotAllowedKind when the input is valid but
-	// conflicts with the state of the system.
-	InvalidInputKind errorKind = "invalid input error"
-
-	// NotAllowedKind means that there was a problem due to the state of
-	// the system not matching the requested operation or input. For
-	// example, trying to create a username that is valid, but is already
-	// taken by another user. Use InvalidInputKind when the input isn't
-	// valid regardless of the state of the system. Use NotFoundKind when
-	// the failure is due to not being able to find a resource.
-	NotAllowedKind errorKind = "not allowed"
-
-	// UnauthorizedKind means that there was an access control problem.
-	UnauthorizedKind errorKind = "unauthorized error"
-
-	// InternalKind means that the function failed for a reason unrelated
-	// to its input or problems working with a remote system. Use this kind
-	// when other error kinds aren't appropriate.
-	InternalKind errorKind = "internal error"
-
-	// NotImplementedKind means that the function isn't implemented.
-	NotImplementedKind errorKind = "not implemented error"
-
-	// GraphqlResponseKind means that the graphql server returned an
-	// error code as part of the graphql response.  This kind of error
-	// is only ever returned by gqlclient calls.  It is set when the
-	// graphql call successfully executes, but the graphql response struct
-	// indicates the graphql request could not be executed due to an
-	// error.  (e.g. mutation.MyMutation.Error.Code == "UNAUTHORIZED")
-	GraphqlResponseKind errorKind = "graphql error response"
-
-	// TransientKhanServiceKind means that there was a problem when contacting
-	// another Khan service that might be resolvable by retrying.
-	TransientKhanServiceKind errorKind = "transient khan service error"
-
-	// KhanServiceKind means that there was a non-transient problem when
-	// contacting another Khan service.
-	KhanServiceKind errorKind = "khan service error"
-
-	// TransientServiceKind means that there was a problem when making a
-	// request to a non-Khan service, e.g. datastore that might be
-	// resolvable by retrying.
-	TransientServiceKind errorKind = "transient service error"
-
-	// ServiceKind means that there was a non-transient problem when making a
-	// request to a non-Khan service, e.g. datastore.
-	ServiceKind errorKind = "service error"
-
-	// UnspecifiedKind means that no error kind was specified. Note that there
-	// isn't a constructor for this kind of error.
-	UnspecifiedKind errorKind = "unspecified error"
-)
+// RegisterKind adds an application-defined error kind, such as
+// "quota exceeded", and returns it. Once registered, the kind is
+// recognized by IsValidKind, GetKind, IsKhanError and Wrap just like the
+// core kinds, and errors of that kind are created with its New method:
+//
+//	QuotaExceededKind, err := errors.RegisterKind("quota exceeded")
+//	...
+//	return QuotaExceededKind.New("Too many requests", errors.Fields{"kaid": kaid})
+//
+// Registering the same name twice, or a name used by a core kind, returns
+// a NotAllowed error. RegisterKind is safe for concurrent use.
+func RegisterKind(name string) (errorKind, error) {
+	kind := errorKind(name)
+	if err := kinds.register(kind); err != nil {
+		return UnspecifiedKind, err
+	}
+	return kind, nil
+}
 
-// String presents the value of the string, like "Not Found"
-// The fmt package (and many others) look for this to print values.
-func (e errorKind) String() string {
-	return string(e)
+// NewKind is like RegisterKind but panics if the kind cannot be
+// registered. It is intended for initializing package-level variables:
+//
+//	var QuotaExceededKind = errors.NewKind("quota exceeded")
+func NewKind(name string) errorKind {
+	kind, err := RegisterKind(name)
+	if err != nil {
+		panic(err)
+	}
+	return kind
 }
 
-func (e errorKind) IsValidKind() bool {
-	switch e {
-	case GraphqlResponseKind,
-		InternalKind,
-		InvalidInputKind,
-		KhanServiceKind,
-		NotAllowedKind,
-		NotFoundKind,
-		NotImplementedKind,
-		ServiceKind,
-		TransientKhanServiceKind,
-		TransientServiceKind,
-		UnauthorizedKind,
-		UnspecifiedKind:
-		return true
-	default:
-		return false
+// LookupKind returns the core or registered error kind with the given
+// name. This is handy when a kind has been sent over the wire as a string.
+func LookupKind(name string) (errorKind, bool) {
+	kind := errorKind(name)
+	if name == "" || !kind.IsValidKind() {
+		return UnspecifiedKind, false
 	}
+	return kind, true
 }
 
 // GetKind returns the non-exported type, which can be annoying to use
 // However, in tests, it can be handy.
 func GetKind(err error) errorKind {
-	var khanerr *khanError
-	if As(err, &khanerr) {
-		return getKind(khanerr)
+	for c := err; c != nil; c = Unwrap(c) {
+		switch e := c.(type) {
+		case *Error:
+			return getKind(e)
+		case *joinError:
+			return e.kind
+		}
 	}
 	var kind errorKind
 	if As(err, &kind) {
@@ -187,14 +171,14 @@ func GetKind(err error) errorKind {
 
 // kind returns the error's kind if defined. Otherwise it searches wrapped
 // errors for a kind. If no kind is found it returns UnspecifiedKind.
-func getKind(e *khanError) errorKind {
+func getKind(e *Error) errorKind {
 	if e == nil {
 		return UnspecifiedKind
 	}
 	if e.kind.IsValidKind() {
 		return e.kind
 	}
-	var khanErr *khanError
+	var khanErr *Error
 	if As(e.wrappedErr, &khanErr) {
 		return getKind(khanErr)
 	}
@@ -0,0 +1,248 @@
+// Code generated by kindgen from kinds/kinds.yaml. DO NOT EDIT.
+
+package errors
+
+// NotFound creates an error of kind NotFoundKind.  args can be
+// (1) an error to wrap
+// (2) a string to use as the error message
+// (3) an errors.Fields{} object of key/value pairs to associate with the error
+// (4) an errors.Source("source-location") to override the default source-loc
+// (5) errors.KeepAll, to keep every error, message, Fields and context given
+// (6) a context.Context, whose errors.WithFields fields are added to the error
+// (7) any number of typed fields made with FieldKey.Of, e.g. KAID.Of(kaid)
+// (8) an errors.Severity, e.g. errors.SeverityWarning, to override the kind's default severity
+// (9) an errors.Public("message") to show users instead of the kind's default public message
+// If you pass more than one error, message, Fields or context, only the last
+// one is used and the others are reported in the InvalidErrArgsKey field,
+// unless you also pass errors.KeepAll. More than one Source, Severity or
+// Public is always reported, even with KeepAll. Typed fields can be passed
+// any number of times. Fields given directly win over the context's fields,
+// which win over the fields of the wrapped error.
+func NotFound(args ...any) error {
+	return newError(NotFoundKind, args...)
+}
+
+// InvalidInput creates an error of kind InvalidInputKind.
+func InvalidInput(args ...any) error {
+	return newError(InvalidInputKind, args...)
+}
+
+// NotAllowed creates an error of kind NotAllowedKind.
+func NotAllowed(args ...any) error {
+	return newError(NotAllowedKind, args...)
+}
+
+// Unauthorized creates an error of kind UnauthorizedKind.
+func Unauthorized(args ...any) error {
+	return newError(UnauthorizedKind, args...)
+}
+
+// Internal creates an error of kind InternalKind.
+func Internal(args ...any) error {
+	return newError(InternalKind, args...)
+}
+
+// NotImplemented creates an error of kind NotImplementedKind.
+func NotImplemented(args ...any) error {
+	return newError(NotImplementedKind, args...)
+}
+
+// GraphqlResponse creates an error of kind GraphqlResponseKind.
+func GraphqlResponse(args ...any) error {
+	return newError(GraphqlResponseKind, args...)
+}
+
+// TransientKhanService creates an error of kind TransientKhanServiceKind.
+func TransientKhanService(args ...any) error {
+	return newError(TransientKhanServiceKind, args...)
+}
+
+// KhanService creates an error of kind KhanServiceKind.
+func KhanService(args ...any) error {
+	return newError(KhanServiceKind, args...)
+}
+
+// TransientService creates an error of kind TransientServiceKind.
+func TransientService(args ...any) error {
+	return newError(TransientServiceKind, args...)
+}
+
+// Service creates an error of kind ServiceKind.
+func Service(args ...any) error {
+	return newError(ServiceKind, args...)
+}
+
+const (
+	// NotFoundKind means that some requested resource wasn't found. If the
+	// resource couldn't be retrieved due to access control use
+	// UnauthorizedKind instead. If the resource couldn't be found because
+	// the input was invalid use InvalidInputKind instead.
+	NotFoundKind errorKind = "not found"
+
+	// InvalidInputKind means that there was a problem with the provided input.
+	// This kind indicates inputs that are problematic regardless of the state
+	// of the system. Use NotAllowedKind when the input is valid but
+	// conflicts with the state of the system.
+	InvalidInputKind errorKind = "invalid input error"
+
+	// NotAllowedKind means that there was a problem due to the state of
+	// the system not matching the requested operation or input. For
+	// example, trying to create a username that is valid, but is already
+	// taken by another user. Use InvalidInputKind when the input isn't
+	// valid regardless of the state of the system. Use NotFoundKind when
+	// the failure is due to not being able to find a resource.
+	NotAllowedKind errorKind = "not allowed"
+
+	// UnauthorizedKind means that there was an access control problem.
+	UnauthorizedKind errorKind = "unauthorized error"
+
+	// InternalKind means that the function failed for a reason unrelated
+	// to its input or problems working with a remote system. Use this kind
+	// when other error kinds aren't appropriate.
+	InternalKind errorKind = "internal error"
+
+	// NotImplementedKind means that the function isn't implemented.
+	NotImplementedKind errorKind = "not implemented error"
+
+	// GraphqlResponseKind means that the graphql server returned an
+	// error code as part of the graphql response.  This kind of error
+	// is only ever returned by gqlclient calls.  It is set when the
+	// graphql call successfully executes, but the graphql response struct
+	// indicates the graphql request could not be executed due to an
+	// error.  (e.g. mutation.MyMutation.Error.Code == "UNAUTHORIZED")
+	GraphqlResponseKind errorKind = "graphql error response"
+
+	// TransientKhanServiceKind means that there was a problem when contacting
+	// another Khan service that might be resolvable by retrying.
+	TransientKhanServiceKind errorKind = "transient khan service error"
+
+	// KhanServiceKind means that there was a non-transient problem when
+	// contacting another Khan service.
+	KhanServiceKind errorKind = "khan service error"
+
+	// TransientServiceKind means that there was a problem when making a
+	// request to a non-Khan service, e.g. datastore that might be
+	// resolvable by retrying.
+	TransientServiceKind errorKind = "transient service error"
+
+	// ServiceKind means that there was a non-transient problem when making a
+	// request to a non-Khan service, e.g. datastore.
+	ServiceKind errorKind = "service error"
+
+	// UnspecifiedKind means that no error kind was specified. Note that there
+	// isn't a constructor for this kind of error.
+	UnspecifiedKind errorKind = "unspecified error"
+)
+
+func (e errorKind) isCoreKind() bool {
+	switch e {
+	case GraphqlResponseKind,
+		InternalKind,
+		InvalidInputKind,
+		KhanServiceKind,
+		NotAllowedKind,
+		NotFoundKind,
+		NotImplementedKind,
+		ServiceKind,
+		TransientKhanServiceKind,
+		TransientServiceKind,
+		UnauthorizedKind,
+		UnspecifiedKind:
+		return true
+	default:
+		return false
+	}
+}
+
+// coreHTTPStatuses is the HTTP status used for each core kind, which
+// httpStatuses starts out with.
+var coreHTTPStatuses = map[errorKind]int{
+	NotFoundKind:             404, // Not Found
+	InvalidInputKind:         400, // Bad Request
+	NotAllowedKind:           409, // Conflict
+	UnauthorizedKind:         403, // Forbidden
+	InternalKind:             500, // Internal Server Error
+	NotImplementedKind:       501, // Not Implemented
+	GraphqlResponseKind:      502, // Bad Gateway
+	TransientKhanServiceKind: 503, // Service Unavailable
+	KhanServiceKind:          502, // Bad Gateway
+	TransientServiceKind:     503, // Service Unavailable
+	ServiceKind:              502, // Bad Gateway
+	UnspecifiedKind:          500, // Internal Server Error
+}
+
+// coreRetryableKinds are the core kinds for which trying again might
+// succeed, which retryableKinds starts out with.
+var coreRetryableKinds = map[errorKind]bool{
+	TransientKhanServiceKind: true,
+	TransientServiceKind:     true,
+}
+
+// coreSeverities is the default severity of each core kind, which
+// kindSeverities starts out with.
+var coreSeverities = map[errorKind]Severity{
+	NotFoundKind:             SeverityInfo,
+	InvalidInputKind:         SeverityInfo,
+	NotAllowedKind:           SeverityInfo,
+	UnauthorizedKind:         SeverityWarning,
+	InternalKind:             SeverityCritical,
+	NotImplementedKind:       SeverityError,
+	GraphqlResponseKind:      SeverityError,
+	TransientKhanServiceKind: SeverityWarning,
+	KhanServiceKind:          SeverityError,
+	TransientServiceKind:     SeverityWarning,
+	ServiceKind:              SeverityError,
+	UnspecifiedKind:          SeverityError,
+}
+
+// kindRanks orders the core kinds from least to most severe, for
+// MostSevereKind.
+var kindRanks = map[errorKind]int{
+	NotFoundKind:             2,
+	InvalidInputKind:         3,
+	NotAllowedKind:           4,
+	UnauthorizedKind:         5,
+	InternalKind:             10,
+	NotImplementedKind:       7,
+	GraphqlResponseKind:      6,
+	TransientKhanServiceKind: 8,
+	KhanServiceKind:          9,
+	TransientServiceKind:     8,
+	ServiceKind:              9,
+	UnspecifiedKind:          0,
+}
+
+// corePublicMessages is the default public message of each core kind,
+// which publicMessages starts out with.
+var corePublicMessages = map[errorKind]string{
+	NotFoundKind:             "Not found",
+	InvalidInputKind:         "Invalid input",
+	NotAllowedKind:           "Not allowed",
+	UnauthorizedKind:         "Not authorized",
+	InternalKind:             "Something went wrong",
+	NotImplementedKind:       "Not implemented",
+	GraphqlResponseKind:      "Something went wrong",
+	TransientKhanServiceKind: "Temporarily unavailable, please try again",
+	KhanServiceKind:          "Something went wrong",
+	TransientServiceKind:     "Temporarily unavailable, please try again",
+	ServiceKind:              "Something went wrong",
+	UnspecifiedKind:          "Something went wrong",
+}
+
+// kindsByHTTPStatus is what kindForHTTPStatus uses for the statuses it
+// knows.
+var kindsByHTTPStatus = map[int]errorKind{
+	400: InvalidInputKind,     // Bad Request
+	401: UnauthorizedKind,     // Unauthorized
+	403: UnauthorizedKind,     // Forbidden
+	404: NotFoundKind,         // Not Found
+	409: NotAllowedKind,       // Conflict
+	410: NotFoundKind,         // Gone
+	412: NotAllowedKind,       // Precondition Failed
+	422: InvalidInputKind,     // Unprocessable Entity
+	429: TransientServiceKind, // Too Many Requests
+	501: NotImplementedKind,   // Not Implemented
+	502: ServiceKind,          // Bad Gateway
+	503: TransientServiceKind, // Service Unavailable
+	504: TransientServiceKind, // Gateway Timeout
+}
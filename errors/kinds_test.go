@@ -0,0 +1,72 @@
+package errors_test
+
+import (
+	"sync"
+
+	"github.com/StevenACoffman/khanerr/errors"
+	"github.com/StevenACoffman/khanerr/errors/errtest"
+)
+
+var quotaExceededKind = errors.NewKind("quota exceeded")
+
+func (es *errorSuite) TestRegisterKind() {
+	es.Require().True(quotaExceededKind.IsValidKind())
+
+	e := quotaExceededKind.New("Too many requests", errors.Fields{"kaid": "123"})
+	es.Require().Equal(quotaExceededKind, errors.GetKind(e))
+	es.Require().True(errors.Is(e, quotaExceededKind))
+	es.Require().True(errors.IsKhanError(e))
+	es.Require().Equal(
+		errors.Fields{"Kind": "quota exceeded", "Message": "Too many requests", "kaid": "123"},
+		errors.GetFields(e))
+
+	e2 := errors.Wrap(e, "attempt", 2)
+	es.Require().Equal(quotaExceededKind, errors.GetKind(e2))
+	es.Require().Equal(quotaExceededKind, errors.GetKind(errors.Wrap(quotaExceededKind)))
+
+	kind, ok := errors.LookupKind("quota exceeded")
+	es.Require().True(ok)
+	es.Require().Equal(quotaExceededKind, kind)
+	_, ok = errors.LookupKind("no such kind")
+	es.Require().False(ok)
+}
+
+func (es *errorSuite) TestRegisterKindConflicts() {
+	_, err := errors.RegisterKind("quota exceeded")
+	es.Require().Equal(errors.NotAllowedKind, errors.GetKind(err))
+
+	_, err = errors.RegisterKind("not found")
+	es.Require().Equal(errors.NotAllowedKind, errors.GetKind(err))
+	es.Require().Equal("not found", errors.GetFields(err)[errors.KindKey])
+
+	_, err = errors.RegisterKind("")
+	es.Require().Equal(errors.InvalidInputKind, errors.GetKind(err))
+
+	es.Require().Panics(func() { errors.NewKind("quota exceeded") })
+}
+
+func (es *errorSuite) TestRegisterKindConcurrently() {
+	var names [10]string
+	for i := range names {
+		names[i] = errtest.KindName("concurrent")
+	}
+	var wg sync.WaitGroup
+	failures := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Every name is registered twice, so exactly half must fail.
+			if _, err := errors.RegisterKind(names[i%10]); err != nil {
+				failures <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(failures)
+	es.Require().Len(failures, 10)
+	for i := 0; i < 10; i++ {
+		_, ok := errors.LookupKind(names[i])
+		es.Require().True(ok)
+	}
+}
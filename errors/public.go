@@ -0,0 +1,55 @@
+package errors
+
+// Public is an error constructor argument holding a message that is safe
+// to show to users, unlike the error's own message and fields, which are
+// meant for our logs:
+//
+//	errors.NotFound("No user with kaid "+kaid, errors.Public("We couldn't find that user"))
+type Public string
+
+// publicMessages is the public message used for each kind by errors that
+// weren't given one. Kinds that aren't in it, like most of those added
+// with RegisterKind, use UnspecifiedKind's.
+var publicMessages = newKindTable(corePublicMessages)
+
+// RegisterPublicMessage sets the public message of errors of kind that
+// weren't given one, which is typically for a kind added with
+// RegisterKind.
+func RegisterPublicMessage(kind errorKind, message string) {
+	publicMessages.set(kind, message)
+}
+
+// kindPublicMessage returns the public message of errors of kind that
+// weren't given one.
+func kindPublicMessage(kind errorKind) string {
+	if message, ok := publicMessages.get(kind); ok {
+		return message
+	}
+	message, _ := publicMessages.get(UnspecifiedKind)
+	return message
+}
+
+// PublicMessage returns the message to show users for err: the outermost
+// message given with Public in the chain of err, or else the public
+// message of its kind, like "Not found" or "Something went wrong". It
+// never includes the error's own message or fields. HTTP responses from
+// NewProblem and WriteProblem use it. It returns "" for a nil error.
+func PublicMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	for c := err; c != nil; {
+		switch v := c.(type) {
+		case *Error:
+			if v.public != "" {
+				return v.public
+			}
+			c = v.Cause()
+		case *joinError:
+			c = nil
+		default:
+			c = Unwrap(c)
+		}
+	}
+	return kindPublicMessage(GetKind(err))
+}
@@ -0,0 +1,51 @@
+package errors_test
+
+import (
+	"fmt"
+	"net/http/httptest"
+
+	"github.com/StevenACoffman/khanerr/errors"
+	"github.com/StevenACoffman/khanerr/errors/errtest"
+)
+
+func (es *errorSuite) TestPublicMessage() {
+	es.Require().Equal("", errors.PublicMessage(nil))
+	es.Require().Equal("Not found", errors.PublicMessage(errors.NotFound("No user 123")))
+	es.Require().Equal("Something went wrong", errors.PublicMessage(errors.Internal("Database down")))
+	es.Require().Equal("Something went wrong", errors.PublicMessage(fmt.Errorf("plain")))
+
+	e := errors.NotFound("No user 123", errors.Public("We couldn't find that user"))
+	es.Require().Equal("We couldn't find that user", errors.PublicMessage(e))
+	es.Require().NotContains(e.Error(), "We couldn't find")
+
+	// The outermost public message wins, and sticks through wrapping.
+	es.Require().Equal("We couldn't find that user",
+		errors.PublicMessage(errors.Wrap(e, "kaid", "123")))
+	es.Require().Equal("We couldn't find that user",
+		errors.PublicMessage(errors.Internal("Unable to load", e)))
+	es.Require().Equal("Please sign in",
+		errors.PublicMessage(errors.Unauthorized(e, errors.Public("Please sign in"))))
+
+	e = errors.NotFound(errors.Public("a"), errors.Public("b"))
+	es.Require().Equal("b", errors.PublicMessage(e))
+	es.Require().Contains(errors.GetFields(e), errors.InvalidErrArgsKey)
+
+	kind := errors.NewKind(errtest.KindName("public test kind"))
+	es.Require().Equal("Something went wrong", errors.PublicMessage(kind.New()))
+	errors.RegisterPublicMessage(kind, "Slow down")
+	es.Require().Equal("Slow down", errors.PublicMessage(kind.New()))
+}
+
+func (es *errorSuite) TestPublicOutputHidesInternals() {
+	e := errors.Internal("Query failed for kaid_123",
+		errors.Fields{"kaid": "kaid_123", "sql": "SELECT * FROM users"},
+		errors.Unauthorized("Token expired", errors.Fields{"token": "abc.def"}))
+
+	rec := httptest.NewRecorder()
+	errors.WriteProblem(rec, e)
+	body := rec.Body.String()
+	for _, internal := range []string{"kaid_123", "SELECT", "Query failed", "Token expired", "abc.def"} {
+		es.Require().NotContains(body, internal)
+	}
+	es.Require().Contains(body, `"detail":"Something went wrong"`)
+}
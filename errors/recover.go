@@ -0,0 +1,187 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+
+	simpler "github.com/StevenACoffman/simplerr/errors"
+)
+
+// The fields of an error made from a recovered panic. They are always set,
+// to "" if they don't apply, so that they are present in the log schema.
+const (
+	// HandledGraphQLPanicKey is set by the graphql error handler when it
+	// is the one that recovered the panic.
+	HandledGraphQLPanicKey = "handledGraphQLPanic"
+	// PanicValueKey is the value passed to panic(), as a string.
+	PanicValueKey = "panicValue"
+	// PanicErrKindKey, PanicErrMessageKey and PanicErrSourceKey describe
+	// the value passed to panic() if it was an error.
+	PanicErrKindKey    = "panicErr.Kind"
+	PanicErrMessageKey = "panicErr.Message"
+	PanicErrSourceKey  = "panicErr.Source"
+)
+
+// isPanicKey returns true for the keys that describe a recovered panic.
+func isPanicKey(key string) bool {
+	switch key {
+	case HandledGraphQLPanicKey, PanicValueKey, PanicErrKindKey,
+		PanicErrMessageKey, PanicErrSourceKey:
+		return true
+	}
+	return false
+}
+
+// Recover turns a panic into an Internal error, which is stored in *errp.
+// It must be deferred directly, so that it can call recover():
+//
+//	func DoThing() (err error) {
+//	    defer errors.Recover(&err)
+//	    ...
+//	}
+//
+// See RecoverValue for what the error looks like.
+func Recover(errp *error) {
+	if v := recover(); v != nil {
+		*errp = recoverValue(v)
+	}
+}
+
+// RecoverValue turns a value returned by recover() into an Internal error,
+// or returns nil if v is nil:
+//
+//	defer func() {
+//	    if err := errors.RecoverValue(recover()); err != nil {
+//	        ...
+//	    }
+//	}()
+//
+// The error has the PanicValueKey field, and the PanicErr fields if v is
+// an error. Its stack trace is that of the panic, unless v is an error
+// with a stack trace of its own, which is then the innermost one. If v is
+// an error it is wrapped, so errors.Is still matches it and its kind, but
+// the error is always an Internal error, since a panic is a bug whatever
+// the panic value was.
+func RecoverValue(v any) error {
+	if v == nil {
+		return nil
+	}
+	return recoverValue(v)
+}
+
+func recoverValue(v any) error {
+	fields := Fields{
+		HandledGraphQLPanicKey: "",
+		PanicValueKey:          "",
+		PanicErrKindKey:        "",
+		PanicErrMessageKey:     "",
+		PanicErrSourceKey:      "",
+	}
+	panicErr := &panicError{stack: panicStack()}
+	if err, ok := v.(error); ok {
+		panicErr.err = err
+		fields[PanicValueKey] = err.Error()
+		fields[PanicErrMessageKey] = err.Error()
+		if IsKhanError(err) {
+			fields[PanicErrKindKey] = GetKind(err).String()
+			if message, ok := GetFields(err)[MessageKey].(string); ok {
+				fields[PanicErrMessageKey] = message
+			}
+			fields[PanicErrSourceKey] = string(GetSource(err))
+		}
+	} else {
+		panicErr.value = fmt.Sprint(v)
+		fields[PanicValueKey] = panicErr.value
+	}
+	return newError(InternalKind, "Recovered from a panic", panicErr, fields)
+}
+
+// panicError holds the stack trace of a panic, in between the Internal
+// error made by RecoverValue and the panic value if that was an error.
+type panicError struct {
+	err   error
+	value string
+	stack *simpler.Stack
+}
+
+func (e *panicError) Error() string {
+	if e.err != nil {
+		return "panic: " + e.err.Error()
+	}
+	return "panic: " + e.value
+}
+
+func (e *panicError) Unwrap() error {
+	return e.err
+}
+
+// StackTrace returns where the panic happened.
+func (e *panicError) StackTrace() *simpler.StackTrace {
+	return e.stack.StackTrace()
+}
+
+// panicStack returns the stack of the goroutine that is panicking, starting
+// at the function that called panic(), or at the caller of RecoverValue if
+// we aren't in a panic.
+func panicStack() *simpler.Stack {
+	// Skip runtime.Callers, panicStack and recoverValue.
+	const skip = 3
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip, pcs)
+	for n == len(pcs) {
+		pcs = make([]uintptr, 2*len(pcs))
+		n = runtime.Callers(skip, pcs)
+	}
+	pcs = pcs[:n]
+
+	// While panicking, the stack is the deferred function, then the
+	// runtime's panic machinery, then the function that panicked.
+	start := 1 // The caller of Recover or RecoverValue.
+	for i, pc := range pcs {
+		if fn := runtime.FuncForPC(pc - 1); fn != nil && fn.Name() == "runtime.gopanic" {
+			start = i + 1
+		}
+	}
+	// Skip runtime.panicmem and the like, for panics raised by the runtime.
+	for start < len(pcs) {
+		fn := runtime.FuncForPC(pcs[start] - 1)
+		if fn == nil || !strings.HasPrefix(fn.Name(), "runtime.") {
+			break
+		}
+		start++
+	}
+	if start >= len(pcs) {
+		start = 0
+	}
+	stack := simpler.Stack(pcs[start:])
+	return &stack
+}
+
+// RecoverHandler is net/http middleware that recovers panics in next. The
+// panic is turned into an Internal error by RecoverValue, passed to report
+// -- if it isn't nil -- for logging, and written as a problem response
+// with WriteProblem.
+//
+// Panics with http.ErrAbortHandler are re-panicked, since that is how a
+// handler asks the server to abort the response.
+func RecoverHandler(next http.Handler, report func(*http.Request, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler { //nolint:errorlint // net/http compares it the same way.
+				panic(v)
+			}
+			err := recoverValue(v)
+			if report != nil {
+				report(r, err)
+			}
+			WriteProblem(w, err)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,94 @@
+package errors_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+func panicsWith(v any) {
+	panic(v)
+}
+
+func recoverFrom(v any) (err error) {
+	defer errors.Recover(&err)
+	panicsWith(v)
+	return nil
+}
+
+func (es *errorSuite) TestRecover() {
+	err := recoverFrom("oh no")
+	es.Require().Equal(errors.InternalKind, errors.GetKind(err))
+	es.Require().Equal(errors.Fields{
+		"Kind":                "internal error",
+		"Message":             "Recovered from a panic",
+		"handledGraphQLPanic": "",
+		"panicValue":          "oh no",
+		"panicErr.Kind":       "",
+		"panicErr.Message":    "",
+		"panicErr.Source":     "",
+	}, errors.GetFields(err))
+
+	frames := errors.GetStackTrace(err)
+	es.Require().NotEmpty(frames)
+	es.Require().Equal(
+		"github.com/StevenACoffman/khanerr/errors_test.panicsWith", frames[0].Function)
+
+	es.Require().NoError(errors.RecoverValue(nil))
+}
+
+func (es *errorSuite) TestRecoverKhanError() {
+	notFound := errors.NotFound("No such user", errors.Source("users.GetUser"))
+	err := recoverFrom(notFound)
+	es.Require().Equal(errors.InternalKind, errors.GetKind(err))
+	es.Require().True(errors.Is(err, errors.NotFoundKind))
+	es.Require().True(errors.Is(err, notFound))
+
+	fields := errors.GetFields(err)
+	es.Require().Equal(errors.NotFoundKind.String(), fields["panicErr.Kind"])
+	es.Require().Equal("No such user", fields["panicErr.Message"])
+	es.Require().Equal("users.GetUser", fields["panicErr.Source"])
+	es.Require().Equal(notFound.Error(), fields["panicValue"])
+	es.Require().Equal("Recovered from a panic", fields["Message"])
+}
+
+func (es *errorSuite) TestRecoverRuntimePanic() {
+	err := func() (err error) {
+		defer errors.Recover(&err)
+		var m map[string]int
+		m["boom"] = 1
+		return nil
+	}()
+	es.Require().Equal(errors.InternalKind, errors.GetKind(err))
+	es.Require().Contains(errors.GetFields(err)["panicValue"], "nil map")
+	es.Require().Equal("assignment to entry in nil map",
+		errors.GetFields(err)["panicErr.Message"])
+
+	frames := errors.GetStackTrace(err)
+	es.Require().NotEmpty(frames)
+	es.Require().Contains(frames[0].Function, "TestRecoverRuntimePanic")
+}
+
+func (es *errorSuite) TestRecoverHandler() {
+	var reported error
+	handler := errors.RecoverHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panicsWith(errors.NotAllowed("Read only"))
+	}), func(_ *http.Request, err error) {
+		reported = err
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	es.Require().Equal(http.StatusInternalServerError, rec.Code)
+	es.Require().Equal(errors.ProblemContentType, rec.Header().Get("Content-Type"))
+	es.Require().True(errors.Is(reported, errors.NotAllowedKind))
+	es.Require().Equal(errors.InternalKind, errors.GetKind(reported))
+
+	abort := errors.RecoverHandler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panicsWith(http.ErrAbortHandler)
+	}), nil)
+	es.Require().PanicsWithValue(http.ErrAbortHandler, func() {
+		abort.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
@@ -0,0 +1,214 @@
+package errors
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sync"
+	"sync/atomic"
+)
+
+// RedactedValue is what redacted field values are replaced with, unless a
+// hash key has been set with SetRedactionHashKey.
+const RedactedValue = "[REDACTED]"
+
+// SecretValue is a field value that is never shown. Make one with Secret.
+type SecretValue struct {
+	value any
+}
+
+// Secret marks v as a sensitive field value, such as a token, so that it
+// is redacted wherever the error's fields are shown, whatever its key:
+//
+//	errors.Unauthorized("Bad token", errors.Fields{"token": errors.Secret(token)})
+//
+// Use GetRawFields to read the value back.
+func Secret(v any) SecretValue {
+	return SecretValue{value: v}
+}
+
+// String returns RedactedValue, so that printing a secret doesn't leak it.
+func (s SecretValue) String() string {
+	return RedactedValue
+}
+
+// GoString returns RedactedValue, for %#v.
+func (s SecretValue) GoString() string {
+	return RedactedValue
+}
+
+// MarshalJSON encodes the secret as RedactedValue.
+func (s SecretValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(RedactedValue)
+}
+
+// LogValue implements slog.LogValuer, logging the secret as RedactedValue.
+func (s SecretValue) LogValue() slog.Value {
+	return slog.StringValue(RedactedValue)
+}
+
+// redactionRules holds the sensitive keys and key patterns, and how their
+// values are redacted. The rules are replaced rather than changed when
+// more are registered, so they can be used without holding a lock.
+type redactionRules struct {
+	keys     map[string]struct{}
+	patterns []*regexp.Regexp
+	hashKey  []byte
+}
+
+var (
+	redactionMu sync.Mutex
+	redaction   atomic.Pointer[redactionRules]
+)
+
+func init() {
+	redaction.Store(&redactionRules{keys: map[string]struct{}{}})
+}
+
+// updateRedaction replaces the redaction rules with a changed copy.
+func updateRedaction(update func(*redactionRules)) {
+	redactionMu.Lock()
+	defer redactionMu.Unlock()
+	old := redaction.Load()
+	rules := &redactionRules{
+		keys:     make(map[string]struct{}, len(old.keys)),
+		patterns: append([]*regexp.Regexp(nil), old.patterns...),
+		hashKey:  old.hashKey,
+	}
+	for k := range old.keys {
+		rules.keys[k] = struct{}{}
+	}
+	update(rules)
+	redaction.Store(rules)
+}
+
+// RegisterSensitiveKeys marks the fields with the given keys, e.g.
+// "email" or "password", as sensitive, so that their values are redacted.
+//
+// Redaction happens when fields are read, by GetFields, GetWrappedErrors,
+// Fields, JSON encoding, logging and the other integrations, and when an
+// error is created, for Error(). So keys should be registered during
+// initialization, before any errors are created.
+func RegisterSensitiveKeys(keys ...string) {
+	updateRedaction(func(rules *redactionRules) {
+		for _, key := range keys {
+			rules.keys[key] = struct{}{}
+		}
+	})
+}
+
+// RegisterSensitivePattern marks the fields whose keys match the regular
+// expression pattern, e.g. "(?i)token|secret", as sensitive, like
+// RegisterSensitiveKeys. It returns an InvalidInput error if pattern
+// doesn't compile.
+func RegisterSensitivePattern(pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return InvalidInput("Invalid sensitive key pattern", err,
+			Fields{"pattern": pattern})
+	}
+	updateRedaction(func(rules *redactionRules) {
+		rules.patterns = append(rules.patterns, re)
+	})
+	return nil
+}
+
+// SetRedactionHashKey makes redacted values a keyed hash of the value,
+// like "[REDACTED:3f2a9c1b0d4e5f67]", rather than RedactedValue, so that
+// errors about the same user or token can be told apart and grouped
+// without showing the value. The same key always gives the same hash for
+// the same value. A nil key goes back to RedactedValue.
+func SetRedactionHashKey(key []byte) {
+	updateRedaction(func(rules *redactionRules) {
+		rules.hashKey = key
+	})
+}
+
+func (r *redactionRules) isSensitive(key string) bool {
+	if _, ok := r.keys[key]; ok {
+		return true
+	}
+	for _, re := range r.patterns {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact returns the value to show for the field key with value v.
+func (r *redactionRules) redact(key string, v any) any {
+	secret, isSecret := v.(SecretValue)
+	if !isSecret && !r.isSensitive(key) {
+		return v
+	}
+	if isSecret {
+		v = secret.value
+	}
+	if r.hashKey == nil {
+		return RedactedValue
+	}
+	mac := hmac.New(sha256.New, r.hashKey)
+	mac.Write([]byte(StringifyField(v)))
+	return "[REDACTED:" + hex.EncodeToString(mac.Sum(nil)[:8]) + "]"
+}
+
+// redactFields returns a copy of fields with the sensitive values redacted.
+func redactFields(fields Fields) Fields {
+	rules := redaction.Load()
+	redacted := make(Fields, len(fields))
+	for k, v := range fields {
+		redacted[k] = rules.redact(k, v)
+	}
+	return redacted
+}
+
+// describeBadArg describes an invalid constructor argument for the
+// InvalidErrArgsKey field, with sensitive values redacted, since the
+// description ends up in Error() and the logs.
+func describeBadArg(arg any) string {
+	rules := redaction.Load()
+	switch v := arg.(type) {
+	case error:
+		return fmt.Sprintf("error(%q)", v.Error())
+	case Fields:
+		return fmt.Sprintf("%#v", redactFields(v))
+	case map[string]any:
+		return fmt.Sprintf("%#v", map[string]any(redactFields(v)))
+	case Field:
+		return fmt.Sprintf("%#v", Field{Key: v.Key, Value: rules.redact(v.Key, v.Value)})
+	case SecretValue:
+		return fmt.Sprintf("%#v", rules.redact("", v))
+	case context.Context:
+		// %#v would show every value in the context, including secrets
+		// and those of other packages.
+		return "context.Context"
+	}
+	return fmt.Sprintf("%#v", arg)
+}
+
+// revealFields returns a copy of fields with the values marked by Secret
+// unwrapped.
+func revealFields(fields Fields) Fields {
+	revealed := make(Fields, len(fields))
+	for k, v := range fields {
+		if secret, ok := v.(SecretValue); ok {
+			v = secret.value
+		}
+		revealed[k] = v
+	}
+	return revealed
+}
+
+// GetRawFields is like GetFields, but without redaction: it returns the
+// real values of sensitive fields and of those marked with Secret. It is
+// meant for code that is allowed to see them, like a secure audit log,
+// so be careful where its result ends up.
+func GetRawFields(err error) Fields {
+	return revealFields(rawFields(err))
+}
@@ -0,0 +1,123 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+// registerRedaction registers the sensitive key and pattern that the
+// redaction tests use, until the end of the test.
+func (es *errorSuite) registerRedaction() {
+	es.T().Cleanup(errors.SaveRedaction())
+	errors.RegisterSensitiveKeys("redactEmail")
+	es.Require().NoError(errors.RegisterSensitivePattern(`^redact.*Token$`))
+}
+
+func (es *errorSuite) TestRedaction() {
+	es.registerRedaction()
+	inner := errors.NotFound("No such user", errors.Fields{
+		"redactEmail": "sal@example.com",
+		"kaid":        "123",
+	})
+	e := errors.Unauthorized("Bad token", inner, errors.Fields{
+		"redactAuthToken": "t0k3n",
+		"sql":             errors.Secret("SELECT * FROM users"),
+	})
+
+	for _, shown := range []string{
+		e.Error(),
+		fmt.Sprintf("%v", e),
+		fmt.Sprintf("%+v", e),
+		fmt.Sprintf("%#v", e),
+		fmt.Sprint(errors.GetFields(e)),
+		fmt.Sprint(errors.GetWrappedErrors(e)),
+		es.marshal(e),
+		es.marshal(es.logJSON(e, true, nil)),
+	} {
+		es.Require().NotContains(shown, "sal@example.com")
+		es.Require().NotContains(shown, "t0k3n")
+		es.Require().NotContains(shown, "SELECT")
+	}
+
+	fields := errors.GetFields(e)
+	es.Require().Equal(errors.RedactedValue, fields["redactEmail"])
+	es.Require().Equal(errors.RedactedValue, fields["redactAuthToken"])
+	es.Require().Equal(errors.RedactedValue, fields["sql"])
+	es.Require().Equal("123", fields["kaid"])
+
+	var khanErr *errors.Error
+	es.Require().True(errors.As(e, &khanErr))
+	es.Require().Equal(errors.RedactedValue, khanErr.Fields()["sql"])
+
+	// The raw values can still be read, even after wrapping.
+	raw := errors.GetRawFields(errors.Wrap(e, "attempt", 2))
+	es.Require().Equal("sal@example.com", raw["redactEmail"])
+	es.Require().Equal("t0k3n", raw["redactAuthToken"])
+	es.Require().Equal("SELECT * FROM users", raw["sql"])
+	es.Require().Equal(
+		errors.RedactedValue, errors.GetFields(errors.Wrap(e, "attempt", 2))["sql"])
+	raw = errors.GetRawFields(errors.Join(e, errors.Internal()))
+	es.Require().Equal("SELECT * FROM users", raw["sql"])
+}
+
+func (es *errorSuite) TestRedactionOfBadArgs() {
+	es.registerRedaction()
+	e := errors.Internal(
+		errors.Fields{"redactEmail": "sal@example.com"},
+		map[string]any{"sql": errors.Secret("SELECT * FROM users")},
+		errors.Fields{"kaid": "123"},
+		errors.Secret("hunter2"),
+		errors.Field{Key: "redactAuthToken", Value: "t0k3n"})
+	details := errors.GetFields(e)[errors.InvalidErrArgsKey]
+	es.Require().NotNil(details)
+
+	for _, shown := range []string{
+		e.Error(),
+		fmt.Sprintf("%+v", e),
+		fmt.Sprint(details),
+		es.marshal(e),
+		fmt.Sprint(errors.GetRawFields(e)),
+	} {
+		es.Require().NotContains(shown, "sal@example.com")
+		es.Require().NotContains(shown, "SELECT")
+		es.Require().NotContains(shown, "hunter2")
+	}
+	es.Require().Contains(fmt.Sprint(details), errors.RedactedValue)
+}
+
+func (es *errorSuite) TestRedactionHash() {
+	es.registerRedaction()
+	errors.SetRedactionHashKey([]byte("test key"))
+
+	first := errors.GetFields(errors.NotFound(errors.Fields{"redactEmail": "sal@example.com"}))
+	second := errors.GetFields(errors.Internal(errors.Fields{"redactEmail": "sal@example.com"}))
+	other := errors.GetFields(errors.Internal(errors.Fields{"redactEmail": "kim@example.com"}))
+	es.Require().Regexp(`^\[REDACTED:[0-9a-f]{16}\]$`, first["redactEmail"])
+	es.Require().Equal(first["redactEmail"], second["redactEmail"])
+	es.Require().NotEqual(first["redactEmail"], other["redactEmail"])
+}
+
+func (es *errorSuite) TestRedactionRulesArePutBack() {
+	// The other tests only register their rules until they end.
+	fields := errors.GetFields(errors.NotFound(errors.Fields{"redactEmail": "sal@example.com"}))
+	es.Require().Equal("sal@example.com", fields["redactEmail"])
+}
+
+func (es *errorSuite) TestSecretValue() {
+	secret := errors.Secret("hunter2")
+	es.Require().Equal(errors.RedactedValue, fmt.Sprint(secret))
+	es.Require().Equal(errors.RedactedValue, fmt.Sprintf("%#v", secret))
+	es.Require().Equal(`"[REDACTED]"`, es.marshal(secret))
+
+	err := errors.RegisterSensitivePattern("(")
+	es.Require().Equal(errors.InvalidInputKind, errors.GetKind(err))
+}
+
+// marshal encodes v as JSON.
+func (es *errorSuite) marshal(v any) string {
+	data, err := json.Marshal(v)
+	es.Require().NoError(err)
+	return string(data)
+}
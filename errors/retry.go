@@ -0,0 +1,133 @@
+package errors
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// The fields that Retry uses to record how many attempts it made, and how
+// many it was allowed to make.
+const (
+	AttemptsKey    = "attempts"
+	MaxAttemptsKey = "maxAttempts"
+)
+
+// retryableKinds are the kinds for which trying again might succeed.
+var retryableKinds = newKindTable(coreRetryableKinds)
+
+// RegisterRetryable sets whether errors of kind are worth retrying, which
+// is typically for a kind added with RegisterKind. Only the transient
+// kinds are retryable by default.
+func RegisterRetryable(kind errorKind, retryable bool) {
+	retryableKinds.set(kind, retryable)
+}
+
+// IsRetryable returns true if the kind of err says that trying again might
+// succeed, i.e. it is TransientKhanServiceKind or TransientServiceKind.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	retryable, _ := retryableKinds.get(GetKind(err))
+	return retryable
+}
+
+// RetryPolicy says how many times, and how often, Retry calls a function.
+type RetryPolicy struct {
+	// MaxAttempts is the most times the function is called, including
+	// the first.
+	MaxAttempts int
+	// InitialBackoff is how long to wait before the second attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff is the longest to wait between attempts.
+	MaxBackoff time.Duration
+	// Multiplier is how much the wait grows after each attempt.
+	Multiplier float64
+	// Jitter is the fraction of each wait that is randomized, so that
+	// clients that failed together don't retry together. It is at most 1;
+	// larger values are treated as 1, so the wait is never negative.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is a reasonable policy for calls to other services.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// backoff returns how long to wait after the given attempt, counting
+// from 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	wait := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		// Move the wait up or down by up to Jitter of itself.
+		jitter := min(p.Jitter, 1)
+		wait += wait * jitter * (2*rand.Float64() - 1) //nolint:gosec // jitter isn't security sensitive.
+	}
+	return time.Duration(wait)
+}
+
+// Retry calls fn until it succeeds, it fails with an error that isn't
+// retryable (see IsRetryable), policy.MaxAttempts is reached, or ctx is
+// done. It waits between attempts with exponential backoff and jitter.
+//
+// If fn never succeeds, Retry returns an error with the kind of the last
+// attempt's error, which wraps the errors from every attempt -- and
+// ctx.Err() if ctx is done -- and has the number of attempts made in the
+// AttemptsKey field. If the last attempt's error has no kind, or there
+// were no attempts, the error is an Internal error.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(context.Context) error) error {
+	var errs []error
+	var lastErr error
+	var message string
+	attempts := 0
+	for {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			errs = append(errs, ctxErr)
+			message = "Context done while retrying"
+			break
+		}
+		attempts++
+		lastErr = fn(ctx)
+		if lastErr == nil {
+			return nil
+		}
+		errs = append(errs, lastErr)
+		if !IsRetryable(lastErr) {
+			message = "Not retrying an error that isn't transient"
+			break
+		}
+		if attempts >= policy.MaxAttempts {
+			message = "Giving up after the maximum number of attempts"
+			break
+		}
+
+		timer := time.NewTimer(policy.backoff(attempts))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+
+	kind := GetKind(lastErr)
+	if kind == UnspecifiedKind {
+		kind = InternalKind
+	}
+	return newError(kind, message, Join(errs...), Fields{
+		AttemptsKey:    attempts,
+		MaxAttemptsKey: policy.MaxAttempts,
+	})
+}
@@ -0,0 +1,126 @@
+package errors_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/StevenACoffman/khanerr/errors"
+	"github.com/StevenACoffman/khanerr/errors/errtest"
+)
+
+// fastRetryPolicy retries without waiting long, to keep the tests quick.
+var fastRetryPolicy = errors.RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     2 * time.Millisecond,
+	Multiplier:     2,
+	Jitter:         0.5,
+}
+
+func (es *errorSuite) TestIsRetryable() {
+	es.Require().True(errors.IsRetryable(errors.TransientService()))
+	es.Require().True(errors.IsRetryable(errors.TransientKhanService()))
+	es.Require().True(errors.IsRetryable(errors.TransientServiceKind))
+	es.Require().False(errors.IsRetryable(errors.Service()))
+	es.Require().False(errors.IsRetryable(errors.NotFound()))
+	es.Require().False(errors.IsRetryable(&_error{}))
+	es.Require().False(errors.IsRetryable(nil))
+
+	// The outermost kind decides.
+	es.Require().False(errors.IsRetryable(errors.Internal(errors.TransientService())))
+	es.Require().True(errors.IsRetryable(errors.TransientService(errors.Internal())))
+
+	kind := errors.NewKind(errtest.KindName("rate limited error"))
+	es.Require().False(errors.IsRetryable(kind.New()))
+	errors.RegisterRetryable(kind, true)
+	es.Require().True(errors.IsRetryable(kind.New()))
+}
+
+func (es *errorSuite) TestRetrySucceeds() {
+	calls := 0
+	err := errors.Retry(context.Background(), fastRetryPolicy, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.TransientService("Try again")
+		}
+		return nil
+	})
+	es.Require().NoError(err)
+	es.Require().Equal(3, calls)
+}
+
+func (es *errorSuite) TestRetryGivesUp() {
+	var attemptErrs []error
+	err := errors.Retry(context.Background(), fastRetryPolicy, func(context.Context) error {
+		attemptErr := errors.TransientKhanService("Try again")
+		attemptErrs = append(attemptErrs, attemptErr)
+		return attemptErr
+	})
+	es.Require().Len(attemptErrs, 3)
+	es.Require().Equal(errors.TransientKhanServiceKind, errors.GetKind(err))
+	for _, attemptErr := range attemptErrs {
+		es.Require().True(errors.Is(err, attemptErr))
+	}
+	fields := errors.GetFields(err)
+	es.Require().Equal(3, fields[errors.AttemptsKey])
+	es.Require().Equal(3, fields[errors.MaxAttemptsKey])
+}
+
+func (es *errorSuite) TestRetryPermanentError() {
+	calls := 0
+	notFound := errors.NotFound("No such user")
+	err := errors.Retry(context.Background(), fastRetryPolicy, func(context.Context) error {
+		calls++
+		return notFound
+	})
+	es.Require().Equal(1, calls)
+	es.Require().Equal(errors.NotFoundKind, errors.GetKind(err))
+	es.Require().True(errors.Is(err, notFound))
+	es.Require().Equal(1, errors.GetFields(err)[errors.AttemptsKey])
+
+	err = errors.Retry(context.Background(), fastRetryPolicy, func(context.Context) error {
+		return &_error{}
+	})
+	es.Require().Equal(errors.InternalKind, errors.GetKind(err))
+}
+
+func (es *errorSuite) TestRetryContextDone() {
+	ctx, cancel := context.WithCancel(context.Background())
+	slowPolicy := errors.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour}
+	calls := 0
+	err := errors.Retry(ctx, slowPolicy, func(context.Context) error {
+		calls++
+		cancel()
+		return errors.TransientService()
+	})
+	es.Require().Equal(1, calls)
+	es.Require().Equal(errors.TransientServiceKind, errors.GetKind(err))
+	es.Require().True(errors.Is(err, context.Canceled))
+	es.Require().Equal(1, errors.GetFields(err)[errors.AttemptsKey])
+
+	err = errors.Retry(ctx, slowPolicy, func(context.Context) error {
+		es.Fail("fn called after the context was done")
+		return nil
+	})
+	es.Require().Equal(errors.InternalKind, errors.GetKind(err))
+	es.Require().True(errors.Is(err, context.Canceled))
+	es.Require().Equal(0, errors.GetFields(err)[errors.AttemptsKey])
+}
+
+func (es *errorSuite) TestRetryBackoff() {
+	policy := errors.RetryPolicy{
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+	es.Require().Equal(10*time.Millisecond, policy.Backoff(1))
+	es.Require().Equal(40*time.Millisecond, policy.Backoff(3))
+
+	// A Jitter over 1 is treated as 1, so the wait is never negative.
+	policy.Jitter = 5
+	for i := 0; i < 100; i++ {
+		wait := policy.Backoff(1)
+		es.Require().GreaterOrEqual(wait, time.Duration(0))
+		es.Require().LessOrEqual(wait, 20*time.Millisecond)
+	}
+}
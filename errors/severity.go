@@ -0,0 +1,173 @@
+package errors
+
+import (
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Severity says how much an error needs attention. Every kind has a
+// default severity, which a single error can override by passing a
+// Severity to its constructor:
+//
+//	errors.NotFound("No such user", errors.SeverityWarning)
+type Severity int
+
+// The severities, from least to most severe. The zero Severity means that
+// none was given.
+const (
+	SeverityDebug Severity = iota + 1
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+// String returns the name of the severity, like "warning".
+func (s Severity) String() string {
+	switch s {
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// SlogLevel returns the slog level to log an error of this severity at.
+// SeverityCritical is four above slog.LevelError, like the gap between the
+// other levels.
+func (s Severity) SlogLevel() slog.Level {
+	switch s {
+	case SeverityDebug:
+		return slog.LevelDebug
+	case SeverityInfo:
+		return slog.LevelInfo
+	case SeverityWarning:
+		return slog.LevelWarn
+	case SeverityCritical:
+		return slog.LevelError + 4
+	}
+	return slog.LevelError
+}
+
+// kindSeverities is the default severity of each kind. Kinds that aren't
+// in it, like most of those added with RegisterKind, are SeverityError.
+var kindSeverities = newKindTable(coreSeverities)
+
+// RegisterSeverity sets the default severity of errors of kind, which is
+// typically for a kind added with RegisterKind.
+func RegisterSeverity(kind errorKind, severity Severity) {
+	kindSeverities.set(kind, severity)
+}
+
+// kindSeverity returns the default severity of errors of kind.
+func kindSeverity(kind errorKind) Severity {
+	if severity, ok := kindSeverities.get(kind); ok {
+		return severity
+	}
+	return SeverityError
+}
+
+// Severity returns the severity that the error was created with, or the
+// default severity of its kind if it wasn't given one. GetSeverity takes
+// the errors it wraps into account too.
+func (e *Error) Severity() Severity {
+	if e == nil {
+		return kindSeverity(UnspecifiedKind)
+	}
+	if e.severity != 0 {
+		return e.severity
+	}
+	return kindSeverity(e.Kind())
+}
+
+// SeverityRule says how GetSeverity picks a severity from the errors in a
+// chain.
+type SeverityRule int
+
+const (
+	// OutermostSeverity picks the severity of the outermost error in the
+	// chain that was given one, so that a severity sticks as an error is
+	// wrapped, unless a caller overrides it again. If no error was given
+	// one, it is the default severity of the kind of the whole chain.
+	OutermostSeverity SeverityRule = iota
+	// MaxSeverity picks the highest severity of any khan error in the
+	// chain, including joined errors, whether it was given to the error
+	// or is the default of its kind.
+	MaxSeverity
+)
+
+// severityRule is the SeverityRule that GetSeverity uses.
+var severityRule atomic.Int32
+
+// SetSeverityRule sets the rule that GetSeverity uses. It is
+// OutermostSeverity by default.
+func SetSeverityRule(rule SeverityRule) {
+	severityRule.Store(int32(rule))
+}
+
+// GetSeverity returns the severity of err, which logging integrations use
+// to pick a log level. It walks the chain of err with the rule set by
+// SetSeverityRule. It returns 0 for a nil error.
+func GetSeverity(err error) Severity {
+	return GetSeverityBy(SeverityRule(severityRule.Load()), err)
+}
+
+// GetSeverityBy is GetSeverity with the given rule, rather than the one
+// set by SetSeverityRule.
+func GetSeverityBy(rule SeverityRule, err error) Severity {
+	if err == nil {
+		return 0
+	}
+	if rule == MaxSeverity {
+		if severity := maxSeverity(err); severity != 0 {
+			return severity
+		}
+		return kindSeverity(GetKind(err))
+	}
+	for c := err; c != nil; {
+		switch v := c.(type) {
+		case *Error:
+			if v.severity != 0 {
+				return v.severity
+			}
+			c = v.Cause()
+		case *joinError:
+			// A joined error has no severity of its own, and there's no
+			// one outermost error among those it joins.
+			c = nil
+		default:
+			c = Unwrap(c)
+		}
+	}
+	return kindSeverity(GetKind(err))
+}
+
+// maxSeverity returns the highest severity of the khan errors in the chain
+// of err, or 0 if there are none.
+func maxSeverity(err error) Severity {
+	var highest Severity
+	for c := err; c != nil; {
+		switch v := c.(type) {
+		case *Error:
+			highest = max(highest, v.Severity())
+			c = v.Cause()
+		case *joinError:
+			highest = max(highest, kindSeverity(v.kind))
+			for _, joined := range v.errs {
+				highest = max(highest, maxSeverity(joined))
+			}
+			c = nil
+		default:
+			c = Unwrap(c)
+		}
+	}
+	return highest
+}
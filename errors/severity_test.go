@@ -0,0 +1,86 @@
+package errors_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/StevenACoffman/khanerr/errors"
+	"github.com/StevenACoffman/khanerr/errors/errtest"
+)
+
+func (es *errorSuite) TestSeverityDefaults() {
+	es.Require().Equal(errors.SeverityInfo, errors.GetSeverity(errors.NotFound()))
+	es.Require().Equal(errors.SeverityWarning, errors.GetSeverity(errors.TransientService()))
+	es.Require().Equal(errors.SeverityCritical, errors.GetSeverity(errors.Internal()))
+	es.Require().Equal(errors.SeverityError, errors.GetSeverity(fmt.Errorf("plain")))
+	es.Require().Equal(errors.Severity(0), errors.GetSeverity(nil))
+	es.Require().Equal("warning", errors.SeverityWarning.String())
+
+	kind := errors.NewKind(errtest.KindName("severity test kind"))
+	es.Require().Equal(errors.SeverityError, errors.GetSeverity(kind.New()))
+	errors.RegisterSeverity(kind, errors.SeverityDebug)
+	es.Require().Equal(errors.SeverityDebug, errors.GetSeverity(kind.New()))
+}
+
+func (es *errorSuite) TestSeverityOverride() {
+	e := errors.NotFound("No such user", errors.SeverityWarning)
+	es.Require().Equal(errors.SeverityWarning, errors.GetSeverity(e))
+	var khanErr *errors.Error
+	es.Require().True(errors.As(e, &khanErr))
+	es.Require().Equal(errors.SeverityWarning, khanErr.Severity())
+
+	// The override sticks through wrapping.
+	es.Require().Equal(errors.SeverityWarning, errors.GetSeverity(errors.Wrap(e, "kaid", "123")))
+	es.Require().Equal(errors.SeverityWarning, errors.GetSeverity(fmt.Errorf("loading: %w", e)))
+
+	e = errors.NotFound(errors.SeverityWarning, errors.SeverityDebug)
+	es.Require().Equal(errors.SeverityDebug, errors.GetSeverity(e))
+	es.Require().Contains(errors.GetFields(e), errors.InvalidErrArgsKey)
+}
+
+func (es *errorSuite) TestSeverityRules() {
+	inner := errors.Internal("Database down")
+	outer := errors.NotFound(inner, errors.SeverityInfo)
+	es.Require().Equal(errors.SeverityInfo, errors.GetSeverityBy(errors.OutermostSeverity, outer))
+	es.Require().Equal(errors.SeverityCritical, errors.GetSeverityBy(errors.MaxSeverity, outer))
+
+	// Without an override, the outermost rule uses the chain's kind.
+	es.Require().Equal(errors.SeverityInfo,
+		errors.GetSeverityBy(errors.OutermostSeverity, errors.NotFound(inner)))
+
+	joined := errors.Combine(errors.FirstKind, errors.NotFound(), errors.Internal())
+	es.Require().Equal(errors.SeverityInfo, errors.GetSeverityBy(errors.OutermostSeverity, joined))
+	es.Require().Equal(errors.SeverityCritical, errors.GetSeverityBy(errors.MaxSeverity, joined))
+
+	errors.SetSeverityRule(errors.MaxSeverity)
+	defer errors.SetSeverityRule(errors.OutermostSeverity)
+	es.Require().Equal(errors.SeverityCritical, errors.GetSeverity(outer))
+}
+
+func (es *errorSuite) TestSlogLevelFromSeverity() {
+	var buf bytes.Buffer
+	next := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(errors.NewSlogHandler(next, &errors.SlogOptions{LevelFromSeverity: true}))
+
+	level := func() any {
+		var record map[string]any
+		es.Require().NoError(json.Unmarshal(buf.Bytes(), &record))
+		buf.Reset()
+		return record["level"]
+	}
+	logger.Error("failed", "err", errors.NotFound())
+	es.Require().Equal("INFO", level())
+	logger.Debug("failed", "err", errors.Internal("boom", errors.SeverityWarning))
+	es.Require().Equal("WARN", level())
+	logger.Info("failed", "err", errors.Internal())
+	es.Require().Equal("ERROR+4", level())
+
+	// Records without errors keep their level, and are still filtered.
+	logger.Warn("slow")
+	es.Require().Equal("WARN", level())
+	logger.Debug("details")
+	logger.Error("failed", "err", errors.NotFound(errors.SeverityDebug))
+	es.Require().Empty(buf.String())
+}
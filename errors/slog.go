@@ -0,0 +1,201 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+)
+
+// The keys of the attributes in the group that an error is logged as.
+const (
+	SlogKindKey    = "kind"
+	SlogMessageKey = "message"
+	SlogFieldsKey  = "fields"
+	SlogStackKey   = "stack"
+)
+
+// SlogOptions controls how errors are expanded when they are logged with a
+// handler from NewSlogHandler.
+type SlogOptions struct {
+	// Keys lists which of SlogKindKey, SlogMessageKey, SlogFieldsKey and
+	// SlogStackKey to include. Nil means all of them.
+	Keys []string
+
+	// FieldKeys lists which fields to include. Nil means all of them.
+	FieldKeys []string
+
+	// LevelFromSeverity makes the handler log each record that has an
+	// error attribute at the level for the error's severity (see
+	// GetSeverity and Severity.SlogLevel), rather than the level it was
+	// logged at. If a record has more than one error, the first is used.
+	//
+	// Since the level isn't known until the record is handled, the
+	// handler's Enabled reports true for every level, and the level is
+	// checked against the next handler in Handle instead.
+	LevelFromSeverity bool
+}
+
+func (o *SlogOptions) levelFromSeverity() bool {
+	return o != nil && o.LevelFromSeverity
+}
+
+func (o *SlogOptions) includes(key string) bool {
+	return o == nil || o.Keys == nil || slices.Contains(o.Keys, key)
+}
+
+func (o *SlogOptions) includesField(key string) bool {
+	return o == nil || o.FieldKeys == nil || slices.Contains(o.FieldKeys, key)
+}
+
+// LogValue implements slog.LogValuer, so that a khan error is logged as a
+// group with its kind, message, fields and stack trace, rather than as the
+// string from Error().
+func (e *Error) LogValue() slog.Value {
+	return errorLogValue(e, nil)
+}
+
+// errorLogValue renders any error as a group like Error.LogValue does.
+// Errors that aren't khan errors just have an unspecified kind and their
+// Error() as the message.
+func errorLogValue(err error, opts *SlogOptions) slog.Value {
+	fields := GetFields(err)
+	var attrs []slog.Attr
+	if opts.includes(SlogKindKey) {
+		attrs = append(attrs, slog.String(SlogKindKey, GetKind(err).String()))
+	}
+	if opts.includes(SlogMessageKey) {
+		message, ok := fields[MessageKey].(string)
+		if !ok {
+			message = err.Error()
+		}
+		attrs = append(attrs, slog.String(SlogMessageKey, message))
+	}
+	if opts.includes(SlogFieldsKey) {
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			if k != KindKey && k != MessageKey && opts.includesField(k) {
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) > 0 {
+			sort.Strings(keys)
+			fieldAttrs := make([]any, len(keys))
+			for i, k := range keys {
+				fieldAttrs[i] = slog.Any(k, fields[k])
+			}
+			attrs = append(attrs, slog.Group(SlogFieldsKey, fieldAttrs...))
+		}
+	}
+	if opts.includes(SlogStackKey) {
+		frames := GetStackTrace(err)
+		if len(frames) > 0 {
+			stack := make([]string, len(frames))
+			for i, frame := range frames {
+				stack[i] = fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line)
+			}
+			attrs = append(attrs, slog.Any(SlogStackKey, stack))
+		}
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// slogHandler expands the errors in records before passing them on.
+type slogHandler struct {
+	next slog.Handler
+	opts *SlogOptions
+}
+
+// NewSlogHandler returns a slog.Handler that passes records on to next,
+// after replacing every attribute whose value is an error -- a khan error
+// or not -- with a group holding the error's kind, message, fields and
+// stack trace. opts may be nil, which includes everything.
+//
+//	logger := slog.New(errors.NewSlogHandler(slog.NewJSONHandler(os.Stderr, nil), nil))
+//	logger.Error("Unable to load user", "err", err)
+func NewSlogHandler(next slog.Handler, opts *SlogOptions) slog.Handler {
+	return &slogHandler{next: next, opts: opts}
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.opts.levelFromSeverity() {
+		// An error in the record may raise its level.
+		return true
+	}
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	level := r.Level
+	if h.opts.levelFromSeverity() {
+		if err := recordError(r); err != nil {
+			level = GetSeverity(err).SlogLevel()
+		}
+		if !h.next.Enabled(ctx, level) {
+			return nil
+		}
+	}
+	expanded := slog.NewRecord(r.Time, level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		expanded.AddAttrs(h.expand(a))
+		return true
+	})
+	return h.next.Handle(ctx, expanded)
+}
+
+// recordError returns the first error among the attributes of r, or nil
+// if there isn't one.
+func recordError(r slog.Record) error {
+	var found error
+	r.Attrs(func(a slog.Attr) bool {
+		found = attrError(a)
+		return found == nil
+	})
+	return found
+}
+
+func attrError(a slog.Attr) error {
+	switch a.Value.Kind() {
+	case slog.KindAny, slog.KindLogValuer:
+		if err, ok := a.Value.Any().(error); ok && err != nil {
+			return err
+		}
+	case slog.KindGroup:
+		for _, ga := range a.Value.Group() {
+			if err := attrError(ga); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		expanded[i] = h.expand(a)
+	}
+	return &slogHandler{next: h.next.WithAttrs(expanded), opts: h.opts}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	return &slogHandler{next: h.next.WithGroup(name), opts: h.opts}
+}
+
+func (h *slogHandler) expand(a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindAny, slog.KindLogValuer:
+		if err, ok := a.Value.Any().(error); ok && err != nil {
+			return slog.Attr{Key: a.Key, Value: errorLogValue(err, h.opts)}
+		}
+	case slog.KindGroup:
+		group := a.Value.Group()
+		expanded := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			expanded[i] = h.expand(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(expanded...)}
+	}
+	return a
+}
@@ -0,0 +1,83 @@
+package errors_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+// logJSON logs err with a JSON handler, optionally wrapped by
+// errors.NewSlogHandler, and returns the decoded "err" attribute.
+func (es *errorSuite) logJSON(err error, expand bool, opts *errors.SlogOptions) any {
+	var buf bytes.Buffer
+	var handler slog.Handler = slog.NewJSONHandler(&buf, nil)
+	if expand {
+		handler = errors.NewSlogHandler(handler, opts)
+	}
+	slog.New(handler).Error("failed", "err", err)
+
+	var record map[string]any
+	es.Require().NoError(json.Unmarshal(buf.Bytes(), &record))
+	return record["err"]
+}
+
+func (es *errorSuite) TestLogValue() {
+	e := errors.NotFound("No such user", errors.Fields{"kaid": "123"})
+	logged, ok := es.logJSON(e, false, nil).(map[string]any)
+	es.Require().True(ok)
+	es.Require().Equal("not found", logged["kind"])
+	es.Require().Equal("No such user", logged["message"])
+	es.Require().Equal(map[string]any{"kaid": "123"}, logged["fields"])
+
+	stack, ok := logged["stack"].([]any)
+	es.Require().True(ok)
+	es.Require().NotEmpty(stack)
+	es.Require().Contains(stack[0], "errors_test.(*errorSuite).TestLogValue")
+}
+
+func (es *errorSuite) TestSlogHandler() {
+	// Without the handler, a wrapped khan error is just a string.
+	e := fmt.Errorf("loading: %w", errors.Internal("boom", errors.Fields{"a": 1, "b": 2}))
+	es.Require().Equal(e.Error(), es.logJSON(e, false, nil))
+
+	logged, ok := es.logJSON(e, true, nil).(map[string]any)
+	es.Require().True(ok)
+	es.Require().Equal("internal error", logged["kind"])
+	es.Require().Equal(map[string]any{"a": 1.0, "b": 2.0}, logged["fields"])
+	es.Require().Contains(logged, "stack")
+
+	logged, ok = es.logJSON(e, true, &errors.SlogOptions{
+		Keys:      []string{errors.SlogKindKey, errors.SlogFieldsKey},
+		FieldKeys: []string{"b"},
+	}).(map[string]any)
+	es.Require().True(ok)
+	es.Require().Equal(map[string]any{
+		"kind":   "internal error",
+		"fields": map[string]any{"b": 2.0},
+	}, logged)
+
+	// Non-khan errors are expanded too.
+	logged, ok = es.logJSON(fmt.Errorf("plain"), true, nil).(map[string]any)
+	es.Require().True(ok)
+	es.Require().Equal(map[string]any{"kind": "unspecified error", "message": "plain"}, logged)
+}
+
+func (es *errorSuite) TestSlogHandlerWithAttrs() {
+	var buf bytes.Buffer
+	handler := errors.NewSlogHandler(slog.NewJSONHandler(&buf, nil),
+		&errors.SlogOptions{Keys: []string{errors.SlogKindKey}})
+	logger := slog.New(handler).
+		With("cause", fmt.Errorf("wrapped: %w", errors.NotAllowed())).
+		WithGroup("req")
+	logger.Info("hi", slog.Group("inner", "err", errors.InvalidInput()))
+
+	var record map[string]any
+	es.Require().NoError(json.Unmarshal(buf.Bytes(), &record))
+	es.Require().Equal(map[string]any{"kind": "not allowed"}, record["cause"])
+	es.Require().Equal(
+		map[string]any{"inner": map[string]any{"err": map[string]any{"kind": "invalid input error"}}},
+		record["req"])
+}
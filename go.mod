@@ -1,14 +1,14 @@
 module github.com/StevenACoffman/khanerr
 
-go 1.20
+go 1.21
 
 require (
 	github.com/StevenACoffman/simplerr v0.0.0-20230419164504-91cf1c91bd28
 	github.com/stretchr/testify v1.8.2
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,67 @@
+package gqlerr
+
+import (
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+// The fields of the errors made by FromResponseErrors and FromErrorCode.
+const (
+	// CodeKey is the code that the GraphQL server gave for the error.
+	CodeKey = "graphqlCode"
+	// PathKey is the path of the field that the error is about.
+	PathKey = "graphqlPath"
+)
+
+// GraphQLCode is the typed form of the CodeKey field:
+//
+//	code, ok := gqlerr.GraphQLCode.Get(err)
+var GraphQLCode = errors.NewFieldKey[string](CodeKey)
+
+// FromResponseErrors turns the errors[] of a GraphQL response into a
+// GraphqlResponse error, or returns nil if there are none. Each error
+// keeps its message, its extensions.code under CodeKey, and its path
+// under PathKey. If there is more than one, they are combined with
+// errors.Join.
+func FromResponseErrors(gqlErrs gqlerror.List) error {
+	errs := make([]error, 0, len(gqlErrs))
+	for _, gqlErr := range gqlErrs {
+		if gqlErr == nil {
+			continue
+		}
+		fields := errors.Fields{}
+		if code, ok := gqlErr.Extensions[CodeExtension].(string); ok {
+			fields[CodeKey] = code
+		}
+		if len(gqlErr.Path) > 0 {
+			fields[PathKey] = gqlErr.Path.String()
+		}
+		errs = append(errs, errors.GraphqlResponse(gqlErr.Message, fields))
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return errors.Join(errs...)
+	}
+}
+
+// FromErrorCode turns the error code returned by a mutation, like
+// resp.UpdateUser.Error.Code, into a GraphqlResponse error with the code
+// under CodeKey, or returns nil if code is "". args are passed on to
+// errors.GraphqlResponse:
+//
+//	if resp.UpdateUser.Error != nil {
+//	    return gqlerr.FromErrorCode(resp.UpdateUser.Error.Code, "Unable to update user")
+//	}
+func FromErrorCode(code string, args ...any) error {
+	if code == "" {
+		return nil
+	}
+	// The full slice expression makes append copy args, rather than write
+	// into the spare capacity of the caller's slice.
+	return errors.GraphqlResponse(append(args[:len(args):len(args)], GraphQLCode.Of(code))...)
+}
@@ -0,0 +1,58 @@
+package gqlerr_test
+
+import (
+	"encoding/json"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/StevenACoffman/khanerr/errors"
+	"github.com/StevenACoffman/khanerr/gqlerr"
+)
+
+func (gs *gqlSuite) TestFromResponseErrors() {
+	gs.Require().NoError(gqlerr.FromResponseErrors(nil))
+
+	var response struct {
+		Errors gqlerror.List `json:"errors"`
+	}
+	gs.Require().NoError(json.Unmarshal([]byte(`{"errors": [
+		{"message": "No such video", "path": ["user", "videos", 0], "extensions": {"code": "NOT_FOUND"}}
+	]}`), &response))
+	e := gqlerr.FromResponseErrors(response.Errors)
+	gs.Require().Equal(errors.GraphqlResponseKind, errors.GetKind(e))
+	gs.Require().Equal(errors.Fields{
+		errors.KindKey:    "graphql error response",
+		errors.MessageKey: "No such video",
+		gqlerr.CodeKey:    "NOT_FOUND",
+		gqlerr.PathKey:    "user.videos[0]",
+	}, errors.GetFields(e))
+
+	e = gqlerr.FromResponseErrors(gqlerror.List{
+		gqlerror.Errorf("Not authorized"),
+		{Message: "Slow down", Extensions: map[string]any{"code": "RATE_LIMITED"}},
+	})
+	gs.Require().Equal(errors.GraphqlResponseKind, errors.GetKind(e))
+	joined, ok := e.(interface{ Unwrap() []error })
+	gs.Require().True(ok)
+	gs.Require().Len(joined.Unwrap(), 2)
+	gs.Require().Contains(e.Error(), "Slow down")
+}
+
+func (gs *gqlSuite) TestFromErrorCode() {
+	gs.Require().NoError(gqlerr.FromErrorCode(""))
+
+	e := gqlerr.FromErrorCode("UNAUTHORIZED", "Unable to update user", errors.Fields{"kaid": "123"})
+	gs.Require().Equal(errors.GraphqlResponseKind, errors.GetKind(e))
+	code, ok := gqlerr.GraphQLCode.Get(e)
+	gs.Require().True(ok)
+	gs.Require().Equal("UNAUTHORIZED", code)
+	gs.Require().Equal("123", errors.GetFields(e)["kaid"])
+	gs.Require().Equal("Unable to update user", errors.GetFields(e)[errors.MessageKey])
+
+	// The caller's args are left alone, even if they have room to spare.
+	args := make([]any, 1, 2)
+	args[0] = "Unable to update user"
+	spare := args[:2]
+	_ = gqlerr.FromErrorCode("UNAUTHORIZED", args...)
+	gs.Require().Nil(spare[1])
+}
@@ -0,0 +1,22 @@
+module github.com/StevenACoffman/khanerr/gqlerr
+
+// The other modules use go 1.21, like the core module, but gqlgen
+// v0.17.95 needs go 1.26.
+go 1.26
+
+replace github.com/StevenACoffman/khanerr => ../
+
+require (
+	github.com/99designs/gqlgen v0.17.95
+	github.com/StevenACoffman/khanerr v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.12.1
+	github.com/vektah/gqlparser/v2 v2.5.37
+)
+
+require (
+	github.com/StevenACoffman/simplerr v0.0.0-20230419164504-91cf1c91bd28 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/sosodev/duration v1.4.0 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+	golang.org/x/sync v0.22.0 // indirect
+)
@@ -0,0 +1,21 @@
+// Code generated by kindgen from kinds/kinds.yaml. DO NOT EDIT.
+
+package gqlerr
+
+import "github.com/StevenACoffman/khanerr/errors"
+
+// codesByKind is what ErrorPresenter uses for extensions.code.
+var codesByKind = map[error]string{
+	errors.NotFoundKind:             "NOT_FOUND",
+	errors.InvalidInputKind:         "INVALID_INPUT",
+	errors.NotAllowedKind:           "NOT_ALLOWED",
+	errors.UnauthorizedKind:         "UNAUTHORIZED",
+	errors.InternalKind:             "INTERNAL",
+	errors.NotImplementedKind:       "NOT_IMPLEMENTED",
+	errors.GraphqlResponseKind:      "GRAPHQL_RESPONSE",
+	errors.TransientKhanServiceKind: "TRANSIENT_KHAN_SERVICE",
+	errors.KhanServiceKind:          "KHAN_SERVICE",
+	errors.TransientServiceKind:     "TRANSIENT_SERVICE",
+	errors.ServiceKind:              "SERVICE",
+	errors.UnspecifiedKind:          "UNSPECIFIED",
+}
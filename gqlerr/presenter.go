@@ -0,0 +1,149 @@
+// Package gqlerr connects khanerr errors to GraphQL, on both sides.
+//
+// On the server, ErrorPresenter and RecoverFunc plug into gqlgen, so that
+// resolver errors are sent to clients as GraphQL errors with the
+// errors.PublicMessage of the error, an extensions.code derived from its
+// kind, and only the fields that are allowed:
+//
+//	srv := handler.New(executableSchema)
+//	srv.SetErrorPresenter(gqlerr.ErrorPresenter("videoID"))
+//	srv.SetRecoverFunc(gqlerr.RecoverFunc(reportError))
+//
+// On the client, FromResponseErrors and FromErrorCode turn the errors in
+// a GraphQL response into GraphqlResponse errors.
+//
+// This package is a separate module so that the core khanerr module does
+// not depend on gqlgen.
+package gqlerr
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+// The extensions that ErrorPresenter adds to GraphQL errors.
+const (
+	// CodeExtension is the code for the error's kind, like NOT_FOUND.
+	CodeExtension = "code"
+	// FieldsExtension holds the allowed fields of the error.
+	FieldsExtension = "fields"
+)
+
+var codesMu sync.RWMutex
+
+// RegisterCode sets the extensions.code used for errors of kind, which is
+// typically a kind added with errors.RegisterKind. Kinds without a code
+// use their name in upper snake case, e.g. QUOTA_EXCEEDED for "quota
+// exceeded".
+func RegisterCode(kind error, code string) {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	codesByKind[kind] = code
+}
+
+// Code returns the extensions.code for the kind of err.
+func Code(err error) string {
+	kind := errors.GetKind(err)
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	if code, ok := codesByKind[kind]; ok {
+		return code
+	}
+	return strings.ToUpper(strings.ReplaceAll(kind.String(), " ", "_"))
+}
+
+// ErrorPresenter returns a gqlgen error presenter that turns errors into
+// GraphQL errors whose message is the errors.PublicMessage of the error,
+// with its Code under CodeExtension and the fields named in allowedFields
+// under FieldsExtension. The path and locations that gqlgen found for the
+// error are kept.
+//
+// Errors that gqlgen made itself, like validation errors, and
+// *gqlerror.Error values made by resolvers that don't wrap another error,
+// are already meant for clients, so they are passed through unchanged.
+// Everything else, including errors that aren't khan errors, only shows
+// its public message, so that internal messages and fields never reach
+// clients.
+func ErrorPresenter(allowedFields ...string) graphql.ErrorPresenterFunc {
+	return func(ctx context.Context, err error) *gqlerror.Error {
+		if err == nil {
+			return nil
+		}
+		var gqlErr *gqlerror.Error
+		if !errors.As(err, &gqlErr) {
+			gqlErr = gqlerror.WrapPath(graphql.GetPath(ctx), err)
+		}
+		if gqlErr.Err == nil {
+			return gqlErr
+		}
+		return present(ctx, gqlErr, allowedFields)
+	}
+}
+
+// present returns a copy of gqlErr that describes the error it wraps.
+func present(ctx context.Context, gqlErr *gqlerror.Error, allowedFields []string) *gqlerror.Error {
+	err := gqlErr.Err
+	presented := &gqlerror.Error{
+		Err:        err,
+		Message:    errors.PublicMessage(err),
+		Path:       gqlErr.Path,
+		Locations:  gqlErr.Locations,
+		Extensions: map[string]any{},
+		Rule:       gqlErr.Rule,
+	}
+	if presented.Path == nil {
+		presented.Path = graphql.GetPath(ctx)
+	}
+	for k, v := range gqlErr.Extensions {
+		presented.Extensions[k] = v
+	}
+	presented.Extensions[CodeExtension] = Code(err)
+
+	fields := errors.GetFields(err)
+	allowed := map[string]any{}
+	for _, key := range allowedFields {
+		if v, ok := fields[key]; ok && key != errors.KindKey && key != errors.MessageKey {
+			allowed[key] = jsonSafe(v)
+		}
+	}
+	if len(allowed) > 0 {
+		presented.Extensions[FieldsExtension] = allowed
+	}
+	return presented
+}
+
+// jsonSafe returns value if it can be encoded as JSON, and otherwise its
+// errors.StringifyField form, so one odd field can't break the response.
+func jsonSafe(value any) any {
+	if _, err := json.Marshal(value); err != nil {
+		return errors.StringifyField(value)
+	}
+	return value
+}
+
+// RecoverFunc returns a gqlgen recover func that turns a panic in a
+// resolver into an Internal error with errors.RecoverValue, with the
+// errors.HandledGraphQLPanicKey field set to the path of the field being
+// resolved. The error is passed to report -- if it isn't nil -- for
+// logging, and then to the error presenter, which only shows clients its
+// public message.
+func RecoverFunc(report func(context.Context, error)) graphql.RecoverFunc {
+	return func(ctx context.Context, v any) error {
+		handled := graphql.GetPath(ctx).String()
+		if handled == "" {
+			handled = "true"
+		}
+		err := errors.Wrap(errors.RecoverValue(v), errors.HandledGraphQLPanicKey, handled)
+		if report != nil {
+			report(ctx, err)
+		}
+		return err
+	}
+}
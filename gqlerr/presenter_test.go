@@ -0,0 +1,110 @@
+package gqlerr_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/stretchr/testify/suite"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/StevenACoffman/khanerr/errors"
+	"github.com/StevenACoffman/khanerr/errors/errtest"
+	"github.com/StevenACoffman/khanerr/gqlerr"
+)
+
+type gqlSuite struct{ suite.Suite }
+
+// resolverError wraps err like gqlgen does before presenting it.
+func resolverError(err error) error {
+	gqlErr := gqlerror.WrapPath(ast.Path{ast.PathName("user"), ast.PathName("videos")}, err)
+	gqlErr.Locations = []gqlerror.Location{{Line: 2, Column: 3}}
+	return gqlErr
+}
+
+func (gs *gqlSuite) TestErrorPresenter() {
+	present := gqlerr.ErrorPresenter("videoID", "ch")
+	e := errors.NotFound("No video abc for kaid_123", errors.Public("No such video"),
+		errors.Fields{"videoID": "abc", "kaid": "kaid_123", "ch": make(chan int)})
+
+	presented := present(context.Background(), resolverError(e))
+	gs.Require().Equal("No such video", presented.Message)
+	gs.Require().Equal("user.videos", presented.Path.String())
+	gs.Require().Equal([]gqlerror.Location{{Line: 2, Column: 3}}, presented.Locations)
+	gs.Require().Equal("NOT_FOUND", presented.Extensions[gqlerr.CodeExtension])
+	gs.Require().Equal(map[string]any{
+		"videoID": "abc",
+		"ch":      errors.StringifyField(errors.GetFields(e)["ch"]),
+	}, presented.Extensions[gqlerr.FieldsExtension])
+	gs.Require().True(errors.Is(presented, errors.NotFoundKind))
+
+	// Without gqlgen's wrapping, the path comes from the context.
+	ctx := graphql.WithPathContext(context.Background(), graphql.NewPathWithField("me"))
+	presented = present(ctx, errors.Unauthorized())
+	gs.Require().Equal("Not authorized", presented.Message)
+	gs.Require().Equal("me", presented.Path.String())
+	gs.Require().Equal("UNAUTHORIZED", presented.Extensions[gqlerr.CodeExtension])
+	gs.Require().NotContains(presented.Extensions, gqlerr.FieldsExtension)
+
+	gs.Require().Nil(present(ctx, nil))
+}
+
+func (gs *gqlSuite) TestErrorPresenterHidesInternals() {
+	e := errors.Internal("Query failed for kaid_123",
+		errors.Fields{"kaid": "kaid_123", "sql": "SELECT * FROM users"},
+		errors.Unauthorized("Token expired", errors.Fields{"token": "abc.def"}))
+	for _, err := range []error{e, fmt.Errorf("Query failed for kaid_123: %w", e)} {
+		presented := gqlerr.ErrorPresenter()(context.Background(), resolverError(err))
+		encoded, jsonErr := json.Marshal(presented)
+		gs.Require().NoError(jsonErr)
+		for _, internal := range []string{"kaid_123", "SELECT", "Query failed", "Token expired", "abc.def"} {
+			gs.Require().NotContains(string(encoded), internal)
+		}
+		gs.Require().Equal("Something went wrong", presented.Message)
+		gs.Require().Equal("INTERNAL", presented.Extensions[gqlerr.CodeExtension])
+	}
+
+	// Errors that aren't khan errors have no kind, and no public message.
+	presented := gqlerr.ErrorPresenter()(context.Background(), resolverError(fmt.Errorf("sql: no rows")))
+	gs.Require().Equal("Something went wrong", presented.Message)
+	gs.Require().Equal("UNSPECIFIED", presented.Extensions[gqlerr.CodeExtension])
+}
+
+func (gs *gqlSuite) TestErrorPresenterPassesThroughGraphQLErrors() {
+	gqlErr := gqlerror.ErrorPathf(ast.Path{ast.PathName("user")}, "must not be null")
+	gs.Require().Same(gqlErr, gqlerr.ErrorPresenter()(context.Background(), gqlErr))
+}
+
+func (gs *gqlSuite) TestRegisterCode() {
+	quotaKind := errors.NewKind(errtest.KindName("gql quota exceeded"))
+	gs.Require().Regexp("^GQL_QUOTA_EXCEEDED_[0-9]+$", gqlerr.Code(quotaKind.New()))
+	gqlerr.RegisterCode(quotaKind, "QUOTA")
+	gs.Require().Equal("QUOTA", gqlerr.Code(quotaKind.New()))
+}
+
+func (gs *gqlSuite) TestRecoverFunc() {
+	var reported error
+	recoverFunc := gqlerr.RecoverFunc(func(_ context.Context, err error) { reported = err })
+	ctx := graphql.WithPathContext(context.Background(), graphql.NewPathWithField("user"))
+
+	err := recoverFunc(ctx, "oops")
+	gs.Require().Same(reported, err)
+	gs.Require().Equal(errors.InternalKind, errors.GetKind(err))
+	fields := errors.GetFields(err)
+	gs.Require().Equal("user", fields[errors.HandledGraphQLPanicKey])
+	gs.Require().Equal("oops", fields[errors.PanicValueKey])
+
+	presented := gqlerr.ErrorPresenter()(ctx, resolverError(err))
+	gs.Require().Equal("Something went wrong", presented.Message)
+	gs.Require().Equal("INTERNAL", presented.Extensions[gqlerr.CodeExtension])
+
+	err = gqlerr.RecoverFunc(nil)(context.Background(), fmt.Errorf("boom"))
+	gs.Require().Equal("true", errors.GetFields(err)[errors.HandledGraphQLPanicKey])
+}
+
+func TestGQL(t *testing.T) {
+	suite.Run(t, new(gqlSuite))
+}
@@ -0,0 +1,21 @@
+module github.com/StevenACoffman/khanerr/grpcerr
+
+go 1.21
+
+replace github.com/StevenACoffman/khanerr => ../
+
+require (
+	github.com/StevenACoffman/khanerr v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.12.1
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240513163218-0867130af1f8
+	google.golang.org/grpc v1.64.0
+	google.golang.org/protobuf v1.34.1
+)
+
+require (
+	github.com/StevenACoffman/simplerr v0.0.0-20230419164504-91cf1c91bd28 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+	golang.org/x/net v0.22.0 // indirect
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+)
@@ -0,0 +1,104 @@
+package grpcerr
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns a server interceptor that converts the
+// errors returned by unary handlers with ToGRPCStatus, sending the fields
+// named in allowedFields.
+func UnaryServerInterceptor(allowedFields ...string) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		_ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		resp, err := handler(ctx, req)
+		return resp, toStatusError(err, allowedFields)
+	}
+}
+
+// StreamServerInterceptor returns a server interceptor that converts the
+// errors returned by streaming handlers with ToGRPCStatus, sending the
+// fields named in allowedFields.
+func StreamServerInterceptor(allowedFields ...string) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		_ *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return toStatusError(handler(srv, ss), allowedFields)
+	}
+}
+
+// UnaryClientInterceptor returns a client interceptor that converts the
+// errors returned by unary calls with FromGRPCStatus.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		return fromStatusError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor returns a client interceptor that converts the
+// errors returned by streaming calls, and by the streams they open, with
+// FromGRPCStatus. io.EOF is passed through unchanged.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, fromStatusError(err)
+		}
+		return &clientStream{ClientStream: cs}, nil
+	}
+}
+
+// clientStream converts the errors of the stream it wraps.
+type clientStream struct {
+	grpc.ClientStream
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	return fromStatusError(s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	return fromStatusError(s.ClientStream.RecvMsg(m))
+}
+
+func (s *clientStream) CloseSend() error {
+	return fromStatusError(s.ClientStream.CloseSend())
+}
+
+func toStatusError(err error, allowedFields []string) error {
+	if err == nil {
+		return nil
+	}
+	return ToGRPCStatus(err, allowedFields...).Err()
+}
+
+func fromStatusError(err error) error {
+	if err == nil || err == io.EOF { //nolint:errorlint // gRPC returns io.EOF unwrapped.
+		return err
+	}
+	return FromGRPCStatus(status.Convert(err))
+}
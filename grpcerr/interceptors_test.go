@@ -0,0 +1,108 @@
+package grpcerr_test
+
+import (
+	"context"
+	"io"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/StevenACoffman/khanerr/errors"
+	"github.com/StevenACoffman/khanerr/grpcerr"
+)
+
+// failingService is a hand-written gRPC service whose only method fails
+// with a khan error, so we don't need generated code for the test.
+var failingService = grpc.ServiceDesc{
+	ServiceName: "khanerr.test.Failing",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Fail",
+		Handler: func(
+			_ any,
+			ctx context.Context,
+			dec func(any) error,
+			interceptor grpc.UnaryServerInterceptor,
+		) (any, error) {
+			in := new(emptypb.Empty)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(context.Context, any) (any, error) {
+				return nil, errors.NotFound("No video abc", errors.Public("No such video"),
+					errors.Fields{"video": "abc", "kaid": "123"})
+			}
+			info := &grpc.UnaryServerInfo{FullMethod: "/khanerr.test.Failing/Fail"}
+			return interceptor(ctx, in, info, handler)
+		},
+	}},
+}
+
+func (gs *grpcSuite) TestUnaryInterceptors() {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.UnaryInterceptor(grpcerr.UnaryServerInterceptor("video")))
+	server.RegisterService(&failingService, struct{}{})
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(grpcerr.UnaryClientInterceptor()))
+	gs.Require().NoError(err)
+	defer conn.Close()
+
+	err = conn.Invoke(context.Background(), "/khanerr.test.Failing/Fail",
+		&emptypb.Empty{}, &emptypb.Empty{})
+	gs.Require().Equal(errors.NotFoundKind, errors.GetKind(err))
+	gs.Require().Equal(codes.NotFound, status.Code(err))
+	gs.Require().Equal("abc", errors.GetFields(err)["video"])
+	gs.Require().NotContains(errors.GetFields(err), "kaid")
+	gs.Require().Equal("No such video", errors.GetFields(err)[errors.MessageKey])
+}
+
+func (gs *grpcSuite) TestStreamServerInterceptor() {
+	interceptor := grpcerr.StreamServerInterceptor()
+	err := interceptor(nil, nil, &grpc.StreamServerInfo{},
+		func(any, grpc.ServerStream) error { return errors.InvalidInput("bad cursor") })
+	gs.Require().Equal(codes.InvalidArgument, status.Code(err))
+
+	err = interceptor(nil, nil, &grpc.StreamServerInfo{},
+		func(any, grpc.ServerStream) error { return nil })
+	gs.Require().NoError(err)
+}
+
+type fakeClientStream struct {
+	grpc.ClientStream
+	recvErr error
+}
+
+func (s *fakeClientStream) RecvMsg(any) error { return s.recvErr }
+
+func (gs *grpcSuite) TestStreamClientInterceptor() {
+	interceptor := grpcerr.StreamClientInterceptor()
+	fake := &fakeClientStream{recvErr: status.Error(codes.Unavailable, "try later")}
+	cs, err := interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/m",
+		func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			return fake, nil
+		})
+	gs.Require().NoError(err)
+
+	err = cs.RecvMsg(nil)
+	gs.Require().Equal(errors.TransientServiceKind, errors.GetKind(err))
+	fake.recvErr = io.EOF
+	gs.Require().Equal(io.EOF, cs.RecvMsg(nil))
+
+	_, err = interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/m",
+		func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			return nil, status.Error(codes.PermissionDenied, "nope")
+		})
+	gs.Require().Equal(errors.UnauthorizedKind, errors.GetKind(err))
+}
@@ -0,0 +1,155 @@
+// Package grpcerr converts khanerr errors to and from gRPC statuses.
+//
+// The error kinds in khanerr are modelled on the gRPC status codes, so
+// each kind maps to a codes.Code. ToGRPCStatus turns an error into a
+// *status.Status for the server side, and FromGRPCStatus turns a status
+// received by a client back into an error of the same kind, with the
+// fields that the server allowed. The kind and fields travel as an
+// errdetails.ErrorInfo, so kinds that share a status code, or that were
+// added with errors.RegisterKind, survive the round trip as long as both
+// sides know about them. The status message is the error's
+// errors.PublicMessage, so internal messages aren't sent to clients.
+//
+// The interceptors in this package apply the conversion automatically.
+// This package is a separate module so that the core khanerr module does
+// not depend on gRPC.
+package grpcerr
+
+import (
+	"strings"
+	"sync"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+// Domain is the ErrorInfo domain used for errors converted by this package.
+const Domain = "khanerr"
+
+// CodeKey is the field that FromGRPCStatus uses to record the status code
+// that the error was converted from.
+const CodeKey = "grpcCode"
+
+// KindMetadataKey is the ErrorInfo metadata key that holds the error's
+// kind. It is lowerCamelCase, as AIP-193 asks for.
+const KindMetadataKey = "kind"
+
+var codesMu sync.RWMutex
+
+// RegisterCode sets the status code used for errors of kind, which is
+// typically a kind added with errors.RegisterKind. Kinds without a code
+// are sent as codes.Unknown.
+func RegisterCode(kind error, code codes.Code) {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	codesByKind[kind] = code
+}
+
+// Code returns the status code for the kind of err.
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	if code, ok := codesByKind[errors.GetKind(err)]; ok {
+		return code
+	}
+	return codes.Unknown
+}
+
+// ToGRPCStatus converts err to a status whose code is derived from the
+// error's kind, and whose message is the error's errors.PublicMessage.
+// The kind and the fields named in allowedFields are attached as an
+// errdetails.ErrorInfo, with the field values converted using
+// errors.StringifyField. Other fields are left out, since most fields are
+// meant for our logs rather than for clients.
+//
+// If err isn't a khan error but already carries a gRPC status, that
+// status is returned unchanged. A nil error gives an OK status.
+func ToGRPCStatus(err error, allowedFields ...string) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	if !errors.IsKhanError(err) {
+		if s, ok := status.FromError(err); ok {
+			return s
+		}
+	}
+
+	kind := errors.GetKind(err)
+	fields := errors.GetFields(err)
+	metadata := map[string]string{KindMetadataKey: kind.String()}
+	for _, k := range allowedFields {
+		if k == KindMetadataKey || k == errors.KindKey || k == errors.MessageKey {
+			continue
+		}
+		if v, ok := fields[k]; ok {
+			metadata[k] = errors.StringifyField(v)
+		}
+	}
+
+	s := status.New(Code(err), errors.PublicMessage(err))
+	withDetails, detailsErr := s.WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason(kind.String()),
+		Domain:   Domain,
+		Metadata: metadata,
+	})
+	if detailsErr != nil {
+		return s
+	}
+	return withDetails
+}
+
+// FromGRPCStatus converts s back into a khan error. The kind comes from
+// the ErrorInfo added by ToGRPCStatus if it names a known kind, and
+// otherwise from the status code. The ErrorInfo metadata becomes the
+// error's fields, along with the status code under CodeKey. The status
+// error itself is wrapped, so status.Code() keeps working on the result.
+//
+// A nil or OK status gives a nil error.
+func FromGRPCStatus(s *status.Status) error {
+	if s == nil || s.Code() == codes.OK {
+		return nil
+	}
+
+	fields := errors.Fields{CodeKey: s.Code().String()}
+	var kind error
+	for _, detail := range s.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != Domain {
+			continue
+		}
+		metadata := info.GetMetadata()
+		name, ok := metadata[KindMetadataKey]
+		if !ok {
+			// Older versions sent the kind under errors.KindKey.
+			name = metadata[errors.KindKey]
+		}
+		if known, ok := errors.LookupKind(name); ok {
+			kind = known
+		}
+		for k, v := range metadata {
+			if k != KindMetadataKey && k != errors.KindKey {
+				fields[k] = v
+			}
+		}
+	}
+	if kind == nil {
+		var ok bool
+		kind, ok = kindsByCode[s.Code()]
+		if !ok {
+			kind = errors.InternalKind
+		}
+	}
+	return errors.GetKind(kind).New(s.Message(), fields, s.Err())
+}
+
+// reason turns a kind like "not found" into an ErrorInfo reason like
+// "NOT_FOUND", as the ErrorInfo documentation asks for.
+func reason(kind string) string {
+	return strings.ToUpper(strings.ReplaceAll(kind, " ", "_"))
+}
@@ -0,0 +1,125 @@
+package grpcerr_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/StevenACoffman/khanerr/errors"
+	"github.com/StevenACoffman/khanerr/errors/errtest"
+	"github.com/StevenACoffman/khanerr/grpcerr"
+)
+
+type grpcSuite struct{ suite.Suite }
+
+func (gs *grpcSuite) TestToGRPCStatus() {
+	e := errors.NotFound("No such user", errors.Fields{"kaid": "123", "tags": []string{"a"}})
+	s := grpcerr.ToGRPCStatus(e, "kaid", "tags", "missing")
+	gs.Require().Equal(codes.NotFound, s.Code())
+	gs.Require().Equal("Not found", s.Message())
+
+	gs.Require().Len(s.Details(), 1)
+	info, ok := s.Details()[0].(*errdetails.ErrorInfo)
+	gs.Require().True(ok)
+	gs.Require().Equal("NOT_FOUND", info.GetReason())
+	gs.Require().Equal(grpcerr.Domain, info.GetDomain())
+	gs.Require().Equal(
+		map[string]string{"kind": "not found", "kaid": "123", "tags": `["a"]`},
+		info.GetMetadata())
+}
+
+func (gs *grpcSuite) TestToGRPCStatusHidesInternals() {
+	e := errors.Internal("Query failed for kaid_123",
+		errors.Fields{"kaid": "kaid_123", "sql": "SELECT * FROM users"},
+		errors.Unauthorized("Token expired", errors.Fields{"token": "abc.def"}))
+	s := grpcerr.ToGRPCStatus(e)
+	gs.Require().Equal("Something went wrong", s.Message())
+
+	encoded := fmt.Sprint(s.Proto())
+	for _, internal := range []string{"kaid_123", "SELECT", "Query failed", "Token expired", "abc.def"} {
+		gs.Require().NotContains(encoded, internal)
+	}
+
+	s = grpcerr.ToGRPCStatus(errors.NotFound("No user 123", errors.Public("No such user")))
+	gs.Require().Equal("No such user", s.Message())
+}
+
+func (gs *grpcSuite) TestToGRPCStatusCodes() {
+	gs.Require().Equal(codes.OK, grpcerr.ToGRPCStatus(nil).Code())
+	gs.Require().Equal(codes.InvalidArgument, grpcerr.Code(errors.InvalidInput()))
+	gs.Require().Equal(codes.FailedPrecondition, grpcerr.Code(errors.NotAllowed()))
+	gs.Require().Equal(codes.PermissionDenied, grpcerr.Code(errors.Unauthorized()))
+	gs.Require().Equal(codes.Internal, grpcerr.Code(errors.Internal()))
+	gs.Require().Equal(codes.Unimplemented, grpcerr.Code(errors.NotImplemented()))
+	gs.Require().Equal(codes.Unavailable, grpcerr.Code(errors.TransientService()))
+	gs.Require().Equal(codes.Unavailable, grpcerr.Code(errors.TransientKhanService()))
+	gs.Require().Equal(codes.Unknown, grpcerr.Code(fmt.Errorf("not khan")))
+
+	// Plain status errors pass through unchanged.
+	s := grpcerr.ToGRPCStatus(status.Error(codes.Aborted, "try again"))
+	gs.Require().Equal(codes.Aborted, s.Code())
+	gs.Require().Equal("try again", s.Message())
+	gs.Require().Empty(s.Details())
+}
+
+func (gs *grpcSuite) TestRoundTrip() {
+	e := errors.NotAllowed("Username sal exists", errors.Public("Username taken"),
+		errors.Fields{"username": "sal", "kaid": "123"})
+	e2 := grpcerr.FromGRPCStatus(grpcerr.ToGRPCStatus(e, "username"))
+	gs.Require().Equal(errors.NotAllowedKind, errors.GetKind(e2))
+	gs.Require().True(errors.Is(e2, errors.NotAllowedKind))
+	gs.Require().Equal(codes.FailedPrecondition, status.Code(e2))
+	gs.Require().Equal(errors.Fields{
+		"Kind":     "not allowed",
+		"Message":  "Username taken",
+		"username": "sal",
+		"grpcCode": "FailedPrecondition",
+	}, errors.GetFields(e2))
+
+	// Kinds that share a status code are told apart by the ErrorInfo.
+	e3 := grpcerr.FromGRPCStatus(grpcerr.ToGRPCStatus(errors.Service("datastore down")))
+	gs.Require().Equal(errors.ServiceKind, errors.GetKind(e3))
+
+	// Older versions sent the kind under "Kind".
+	s, err := status.New(codes.Internal, "Something went wrong").WithDetails(&errdetails.ErrorInfo{
+		Reason:   "KHAN_SERVICE_ERROR",
+		Domain:   grpcerr.Domain,
+		Metadata: map[string]string{"Kind": "khan service error", "kaid": "123"},
+	})
+	gs.Require().NoError(err)
+	e4 := grpcerr.FromGRPCStatus(s)
+	gs.Require().Equal(errors.KhanServiceKind, errors.GetKind(e4))
+	gs.Require().Equal("123", errors.GetFields(e4)["kaid"])
+}
+
+func (gs *grpcSuite) TestRegisteredKind() {
+	conflictKind := errors.NewKind(errtest.KindName("grpc conflict"))
+	grpcerr.RegisterCode(conflictKind, codes.AlreadyExists)
+
+	s := grpcerr.ToGRPCStatus(conflictKind.New("Already exists"))
+	gs.Require().Equal(codes.AlreadyExists, s.Code())
+	e := grpcerr.FromGRPCStatus(s)
+	gs.Require().Equal(conflictKind, errors.GetKind(e))
+}
+
+func (gs *grpcSuite) TestFromGRPCStatus() {
+	gs.Require().NoError(grpcerr.FromGRPCStatus(nil))
+	gs.Require().NoError(grpcerr.FromGRPCStatus(status.New(codes.OK, "")))
+
+	// Statuses from servers that don't use khanerr get a kind from the code.
+	e := grpcerr.FromGRPCStatus(status.New(codes.Unauthenticated, "who are you?"))
+	gs.Require().Equal(errors.UnauthorizedKind, errors.GetKind(e))
+	gs.Require().Equal("who are you?", errors.GetFields(e)[errors.MessageKey])
+	gs.Require().Equal("Unauthenticated", errors.GetFields(e)[grpcerr.CodeKey])
+
+	e = grpcerr.FromGRPCStatus(status.New(codes.Code(42), "what?"))
+	gs.Require().Equal(errors.InternalKind, errors.GetKind(e))
+}
+
+func TestGRPC(t *testing.T) {
+	suite.Run(t, new(grpcSuite))
+}
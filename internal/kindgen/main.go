@@ -0,0 +1,489 @@
+// Command kindgen generates the code for the core error kinds from their
+// definitions in kinds/kinds.yaml (or a JSON file with the same shape):
+//
+//   - the kind constants, constructors, isCoreKind switch and kind tables
+//     of the errors package;
+//   - the table of gRPC codes in grpcerr;
+//   - the table of GraphQL error codes in gqlerr;
+//   - TypeScript and protobuf enums, for other languages.
+//
+// It is run by go generate in the errors package. With -check, it writes
+// nothing, and fails if any of the files is not what it would generate,
+// so that CI can catch definitions that were changed without running it.
+//
+// kindgen doesn't use the errors package itself, so that it still runs
+// when the generated code is broken.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kind is the definition of one error kind. See kinds/kinds.yaml.
+type kind struct {
+	Name           string `yaml:"name"`
+	GoName         string `yaml:"goName"`
+	Constructor    bool   `yaml:"constructor"`
+	Description    string `yaml:"description"`
+	ConstructorDoc string `yaml:"constructorDoc"`
+	GRPCCode       string `yaml:"grpcCode"`
+	HTTPStatus     int    `yaml:"httpStatus"`
+	Retryable      bool   `yaml:"retryable"`
+	Severity       string `yaml:"severity"`
+	Rank           int    `yaml:"rank"`
+	PublicMessage  string `yaml:"publicMessage"`
+	Number         int    `yaml:"number"`
+
+	FromGRPCCodes    []string `yaml:"fromGrpcCodes"`
+	FromHTTPStatuses []int    `yaml:"fromHttpStatuses"`
+}
+
+// Const returns the name of the kind's constant.
+func (k kind) Const() string {
+	return k.GoName + "Kind"
+}
+
+// GraphQLCode returns the kind's GraphQL extensions.code, like NOT_FOUND.
+func (k kind) GraphQLCode() string {
+	return strings.TrimPrefix(k.ProtoName(), "ERROR_KIND_")
+}
+
+// ProtoName returns the name of the kind's protobuf enum value, like
+// ERROR_KIND_NOT_FOUND.
+func (k kind) ProtoName() string {
+	var buf strings.Builder
+	buf.WriteString("ERROR_KIND")
+	for i, r := range k.GoName {
+		if i == 0 || unicode.IsUpper(r) {
+			buf.WriteByte('_')
+		}
+		buf.WriteRune(unicode.ToUpper(r))
+	}
+	return buf.String()
+}
+
+// SeverityConst returns the name of the constant for the kind's default
+// severity, like SeverityWarning.
+func (k kind) SeverityConst() string {
+	return "Severity" + strings.ToUpper(k.Severity[:1]) + k.Severity[1:]
+}
+
+// HTTPStatusText returns the name of the kind's HTTP status.
+func (k kind) HTTPStatusText() string {
+	return http.StatusText(k.HTTPStatus)
+}
+
+// grpcCodes are the names of the codes in google.golang.org/grpc/codes,
+// and their values.
+var grpcCodes = map[string]int{
+	"OK": 0, "Canceled": 1, "Unknown": 2, "InvalidArgument": 3,
+	"DeadlineExceeded": 4, "NotFound": 5, "AlreadyExists": 6,
+	"PermissionDenied": 7, "ResourceExhausted": 8,
+	"FailedPrecondition": 9, "Aborted": 10, "OutOfRange": 11,
+	"Unimplemented": 12, "Internal": 13, "Unavailable": 14,
+	"DataLoss": 15, "Unauthenticated": 16,
+}
+
+// severities are the names of the errors.Severity levels.
+var severities = map[string]bool{
+	"debug": true, "info": true, "warning": true, "error": true, "critical": true,
+}
+
+var goIdentifier = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+// load reads the kind definitions in path, which may be YAML or JSON.
+func load(path string) ([]kind, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var kinds []kind
+	// YAML is a superset of JSON, so this reads both.
+	if err := yaml.Unmarshal(data, &kinds); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validate(kinds); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return kinds, nil
+}
+
+// validate checks that the definitions can be generated, and that the
+// names and numbers that must be unique are.
+func validate(kinds []kind) error {
+	names := map[string]bool{}
+	goNames := map[string]bool{}
+	numbers := map[int]string{}
+	fromCodes := map[string]string{}
+	fromStatuses := map[int]string{}
+	for i, k := range kinds {
+		switch {
+		case k.Name == "":
+			return fmt.Errorf("kind %d has no name", i)
+		case names[k.Name]:
+			return fmt.Errorf("kind %q is defined twice", k.Name)
+		case !goIdentifier.MatchString(k.GoName):
+			return fmt.Errorf("kind %q: goName %q is not an exported Go name", k.Name, k.GoName)
+		case goNames[k.GoName]:
+			return fmt.Errorf("kind %q: goName %q is used twice", k.Name, k.GoName)
+		case strings.TrimSpace(k.Description) == "":
+			return fmt.Errorf("kind %q has no description", k.Name)
+		case !isGRPCCode(k.GRPCCode):
+			return fmt.Errorf("kind %q: %q is not a gRPC code", k.Name, k.GRPCCode)
+		case http.StatusText(k.HTTPStatus) == "":
+			return fmt.Errorf("kind %q: %d is not an HTTP status", k.Name, k.HTTPStatus)
+		case !severities[k.Severity]:
+			return fmt.Errorf("kind %q: %q is not a severity", k.Name, k.Severity)
+		case k.Rank < 0 || k.Rank > 10:
+			return fmt.Errorf("kind %q: rank %d is not from 0 to 10", k.Name, k.Rank)
+		case strings.TrimSpace(k.PublicMessage) == "":
+			return fmt.Errorf("kind %q has no publicMessage", k.Name)
+		case numbers[k.Number] != "":
+			return fmt.Errorf("kinds %q and %q have the same number %d",
+				numbers[k.Number], k.Name, k.Number)
+		}
+		for _, code := range k.FromGRPCCodes {
+			switch {
+			case !isGRPCCode(code):
+				return fmt.Errorf("kind %q: %q is not a gRPC code", k.Name, code)
+			case fromCodes[code] != "":
+				return fmt.Errorf("kinds %q and %q are both made from gRPC code %s",
+					fromCodes[code], k.Name, code)
+			}
+			fromCodes[code] = k.Name
+		}
+		for _, status := range k.FromHTTPStatuses {
+			switch {
+			case http.StatusText(status) == "":
+				return fmt.Errorf("kind %q: %d is not an HTTP status", k.Name, status)
+			case fromStatuses[status] != "":
+				return fmt.Errorf("kinds %q and %q are both made from HTTP status %d",
+					fromStatuses[status], k.Name, status)
+			}
+			fromStatuses[status] = k.Name
+		}
+		names[k.Name] = true
+		goNames[k.GoName] = true
+		numbers[k.Number] = k.Name
+	}
+	if numbers[0] == "" {
+		return fmt.Errorf("no kind has number 0, which protobuf enums need")
+	}
+	return nil
+}
+
+func isGRPCCode(name string) bool {
+	_, ok := grpcCodes[name]
+	return ok
+}
+
+// fromGRPCCode is a gRPC code, and the kind that it is turned into.
+type fromGRPCCode struct {
+	Code, Const string
+}
+
+// fromHTTPStatus is an HTTP status, and the kind that it is turned into.
+type fromHTTPStatus struct {
+	Status int
+	Const  string
+}
+
+// Text returns the name of the status.
+func (f fromHTTPStatus) Text() string {
+	return http.StatusText(f.Status)
+}
+
+// comment turns text into a comment, with each line starting with prefix.
+func comment(prefix, text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(prefix+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+var funcs = template.FuncMap{
+	"comment": comment,
+	// describe puts the name of the kind in front of its description.
+	"describe": func(prefix, name, description string) string {
+		return comment(prefix, name+" "+strings.TrimSpace(description))
+	},
+	"sortByConst": func(kinds []kind) []kind {
+		sorted := append([]kind(nil), kinds...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].Const() < sorted[j].Const() })
+		return sorted
+	},
+	"sortByNumber": func(kinds []kind) []kind {
+		sorted := append([]kind(nil), kinds...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].Number < sorted[j].Number })
+		return sorted
+	},
+	// fromGRPCCodes lists the fromGrpcCodes of all the kinds, in code order.
+	"fromGRPCCodes": func(kinds []kind) []fromGRPCCode {
+		var from []fromGRPCCode
+		for _, k := range kinds {
+			for _, code := range k.FromGRPCCodes {
+				from = append(from, fromGRPCCode{Code: code, Const: k.Const()})
+			}
+		}
+		sort.Slice(from, func(i, j int) bool { return grpcCodes[from[i].Code] < grpcCodes[from[j].Code] })
+		return from
+	},
+	// fromHTTPStatuses lists the fromHttpStatuses of all the kinds, in
+	// status order.
+	"fromHTTPStatuses": func(kinds []kind) []fromHTTPStatus {
+		var from []fromHTTPStatus
+		for _, k := range kinds {
+			for _, status := range k.FromHTTPStatuses {
+				from = append(from, fromHTTPStatus{Status: status, Const: k.Const()})
+			}
+		}
+		sort.Slice(from, func(i, j int) bool { return from[i].Status < from[j].Status })
+		return from
+	},
+}
+
+const header = "Code generated by kindgen from kinds/kinds.yaml. DO NOT EDIT."
+
+var goTemplate = template.Must(template.New("go").Funcs(funcs).Parse(`// ` + header + `
+
+package errors
+{{range .}}{{if .Constructor}}
+{{if .ConstructorDoc}}{{comment "// " .ConstructorDoc}}{{else}}// {{.GoName}} creates an error of kind {{.Const}}.{{end}}
+func {{.GoName}}(args ...any) error {
+	return newError({{.Const}}, args...)
+}
+{{end}}{{end}}
+const (
+{{- range $i, $k := .}}{{if $i}}
+{{end}}
+{{describe "\t// " .Const .Description}}
+	{{.Const}} errorKind = {{printf "%q" .Name}}
+{{- end}}
+)
+
+func (e errorKind) isCoreKind() bool {
+	switch e {
+	case {{range $i, $k := sortByConst .}}{{if $i}},
+		{{end}}{{.Const}}{{end}}:
+		return true
+	default:
+		return false
+	}
+}
+
+// coreHTTPStatuses is the HTTP status used for each core kind, which
+// httpStatuses starts out with.
+var coreHTTPStatuses = map[errorKind]int{
+{{- range .}}
+	{{.Const}}: {{.HTTPStatus}}, // {{.HTTPStatusText}}
+{{- end}}
+}
+
+// coreRetryableKinds are the core kinds for which trying again might
+// succeed, which retryableKinds starts out with.
+var coreRetryableKinds = map[errorKind]bool{
+{{- range .}}{{if .Retryable}}
+	{{.Const}}: true,
+{{- end}}{{end}}
+}
+
+// coreSeverities is the default severity of each core kind, which
+// kindSeverities starts out with.
+var coreSeverities = map[errorKind]Severity{
+{{- range .}}
+	{{.Const}}: {{.SeverityConst}},
+{{- end}}
+}
+
+// kindRanks orders the core kinds from least to most severe, for
+// MostSevereKind.
+var kindRanks = map[errorKind]int{
+{{- range .}}
+	{{.Const}}: {{.Rank}},
+{{- end}}
+}
+
+// corePublicMessages is the default public message of each core kind,
+// which publicMessages starts out with.
+var corePublicMessages = map[errorKind]string{
+{{- range .}}
+	{{.Const}}: {{printf "%q" .PublicMessage}},
+{{- end}}
+}
+
+// kindsByHTTPStatus is what kindForHTTPStatus uses for the statuses it
+// knows.
+var kindsByHTTPStatus = map[int]errorKind{
+{{- range fromHTTPStatuses .}}
+	{{.Status}}: {{.Const}}, // {{.Text}}
+{{- end}}
+}
+`))
+
+var grpcTemplate = template.Must(template.New("grpc").Funcs(funcs).Parse(`// ` + header + `
+
+package grpcerr
+
+import (
+	"google.golang.org/grpc/codes"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+// codesByKind is what ToGRPCStatus uses to pick a status code.
+var codesByKind = map[error]codes.Code{
+{{- range .}}
+	errors.{{.Const}}: codes.{{.GRPCCode}},
+{{- end}}
+}
+
+// kindsByCode is what FromGRPCStatus uses when the status has no
+// ErrorInfo naming a known kind, e.g. because it came from a server that
+// doesn't use khanerr.
+var kindsByCode = map[codes.Code]error{
+{{- range fromGRPCCodes .}}
+	codes.{{.Code}}: errors.{{.Const}},
+{{- end}}
+}
+`))
+
+var gqlTemplate = template.Must(template.New("gql").Funcs(funcs).Parse(`// ` + header + `
+
+package gqlerr
+
+import "github.com/StevenACoffman/khanerr/errors"
+
+// codesByKind is what ErrorPresenter uses for extensions.code.
+var codesByKind = map[error]string{
+{{- range .}}
+	errors.{{.Const}}: {{printf "%q" .GraphQLCode}},
+{{- end}}
+}
+`))
+
+var tsTemplate = template.Must(template.New("ts").Funcs(funcs).Parse(`// ` + header + `
+
+/** The names of the core error kinds, as they are logged and sent over the wire. */
+export const ErrorKind = {
+{{- range .}}
+    /**
+{{describe "     * " .GoName .Description}}
+     */
+    {{.GoName}}: {{printf "%q" .Name}},
+{{- end}}
+} as const;
+
+export type ErrorKind = (typeof ErrorKind)[keyof typeof ErrorKind];
+`))
+
+var protoTemplate = template.Must(template.New("proto").Funcs(funcs).Parse(`// ` + header + `
+
+syntax = "proto3";
+
+package khanerr;
+
+// ErrorKind is a core error kind. Each value's comment starts with the
+// kind's name, which is how it is logged and sent in JSON.
+enum ErrorKind {
+{{- range sortByNumber .}}
+{{describe "  // " (printf "%q" .Name) .Description}}
+  {{.ProtoName}} = {{.Number}};
+{{- end}}
+}
+`))
+
+// output is a file that kindgen generates.
+type output struct {
+	path     string
+	template *template.Template
+	gofmt    bool
+}
+
+// render returns the contents of out for kinds.
+func (out output) render(kinds []kind) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := out.template.Execute(&buf, kinds); err != nil {
+		return nil, err
+	}
+	if !out.gofmt {
+		return buf.Bytes(), nil
+	}
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s: generated invalid Go: %w", out.path, err)
+	}
+	return formatted, nil
+}
+
+// generate writes each of outputs for the kinds defined in defs or, if
+// check is true, returns an error if any of them is out of date.
+func generate(defs string, outputs []output, check bool, stderr io.Writer) error {
+	kinds, err := load(defs)
+	if err != nil {
+		return err
+	}
+	stale := 0
+	for _, out := range outputs {
+		data, err := out.render(kinds)
+		if err != nil {
+			return err
+		}
+		if !check {
+			if err := os.WriteFile(out.path, data, 0o644); err != nil {
+				return err
+			}
+			continue
+		}
+		existing, err := os.ReadFile(out.path)
+		if err != nil || !bytes.Equal(existing, data) {
+			fmt.Fprintf(stderr, "kindgen: %s is out of date\n", out.path)
+			stale++
+		}
+	}
+	if stale > 0 {
+		return fmt.Errorf("%d generated files are out of date; run go generate ./errors", stale)
+	}
+	return nil
+}
+
+func main() {
+	defs := flag.String("defs", "kinds.yaml", "the YAML or JSON file that defines the kinds")
+	goOut := flag.String("go", "", "where to write the errors package's Go code")
+	grpcOut := flag.String("grpc", "", "where to write grpcerr's Go code")
+	gqlOut := flag.String("gql", "", "where to write gqlerr's Go code")
+	tsOut := flag.String("ts", "", "where to write the TypeScript enum")
+	protoOut := flag.String("proto", "", "where to write the protobuf enum")
+	check := flag.Bool("check", false, "fail if the files are out of date, instead of writing them")
+	flag.Parse()
+
+	var outputs []output
+	for _, out := range []output{
+		{path: *goOut, template: goTemplate, gofmt: true},
+		{path: *grpcOut, template: grpcTemplate, gofmt: true},
+		{path: *gqlOut, template: gqlTemplate, gofmt: true},
+		{path: *tsOut, template: tsTemplate},
+		{path: *protoOut, template: protoTemplate},
+	} {
+		if out.path != "" {
+			outputs = append(outputs, out)
+		}
+	}
+	if err := generate(*defs, outputs, *check, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "kindgen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// repoOutputs are the files that go generate writes, relative to this
+// directory.
+func repoOutputs() []output {
+	return []output{
+		{path: "../../errors/kinds_gen.go", template: goTemplate, gofmt: true},
+		{path: "../../grpcerr/kinds_gen.go", template: grpcTemplate, gofmt: true},
+		{path: "../../gqlerr/kinds_gen.go", template: gqlTemplate, gofmt: true},
+		{path: "../../kinds/kinds.ts", template: tsTemplate},
+		{path: "../../kinds/kinds.proto", template: protoTemplate},
+	}
+}
+
+func TestGeneratedFilesAreCurrent(t *testing.T) {
+	var stderr bytes.Buffer
+	err := generate("../../kinds/kinds.yaml", repoOutputs(), true, &stderr)
+	require.NoError(t, err, "%s", stderr.String())
+}
+
+func TestCheckStale(t *testing.T) {
+	dir := t.TempDir()
+	out := output{path: filepath.Join(dir, "kinds.ts"), template: tsTemplate}
+	require.NoError(t, os.WriteFile(out.path, []byte("stale"), 0o600))
+
+	var stderr bytes.Buffer
+	err := generate("../../kinds/kinds.yaml", []output{out}, true, &stderr)
+	require.Error(t, err)
+	require.Contains(t, stderr.String(), "kinds.ts is out of date")
+	data, err := os.ReadFile(out.path)
+	require.NoError(t, err)
+	require.Equal(t, "stale", string(data))
+
+	require.NoError(t, generate("../../kinds/kinds.yaml", []output{out}, false, &stderr))
+	require.NoError(t, generate("../../kinds/kinds.yaml", []output{out}, true, &stderr))
+}
+
+func TestLoadJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kinds.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{
+		"name": "unspecified error", "goName": "Unspecified",
+		"description": "means that no error kind was specified.",
+		"grpcCode": "Unknown", "httpStatus": 500, "severity": "error",
+		"publicMessage": "Something went wrong"
+	}, {
+		"name": "quota exceeded", "goName": "QuotaExceeded", "constructor": true,
+		"description": "means that a quota ran out.",
+		"grpcCode": "ResourceExhausted", "httpStatus": 429,
+		"retryable": true, "severity": "warning", "rank": 6, "number": 1,
+		"publicMessage": "Too many requests",
+		"fromGrpcCodes": ["ResourceExhausted"], "fromHttpStatuses": [429]
+	}]`), 0o600))
+
+	kinds, err := load(path)
+	require.NoError(t, err)
+	require.Len(t, kinds, 2)
+	require.Equal(t, "QuotaExceededKind", kinds[1].Const())
+	require.Equal(t, "ERROR_KIND_QUOTA_EXCEEDED", kinds[1].ProtoName())
+	require.Equal(t, "QUOTA_EXCEEDED", kinds[1].GraphQLCode())
+	require.True(t, kinds[1].Retryable)
+
+	data, err := output{template: goTemplate, gofmt: true}.render(kinds)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "func QuotaExceeded(args ...any) error {")
+	require.Contains(t, string(data), "QuotaExceededKind: 429, // Too Many Requests")
+	require.Contains(t, string(data), "QuotaExceededKind: SeverityWarning,")
+	require.Contains(t, string(data), `QuotaExceededKind: "Too many requests",`)
+	require.Contains(t, string(data), "429: QuotaExceededKind, // Too Many Requests")
+
+	data, err = output{template: grpcTemplate, gofmt: true}.render(kinds)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "codes.ResourceExhausted: errors.QuotaExceededKind,")
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() kind {
+		return kind{
+			Name: "unspecified error", GoName: "Unspecified", Description: "means nothing.",
+			GRPCCode: "Unknown", HTTPStatus: 500, Severity: "error",
+			PublicMessage: "Something went wrong",
+		}
+	}
+	require.NoError(t, validate([]kind{valid()}))
+
+	for name, change := range map[string]func(*kind){
+		"no name":         func(k *kind) { k.Name = "" },
+		"bad Go name":     func(k *kind) { k.GoName = "unspecified" },
+		"no doc":          func(k *kind) { k.Description = " " },
+		"bad gRPC code":   func(k *kind) { k.GRPCCode = "Oops" },
+		"bad status":      func(k *kind) { k.HTTPStatus = 999 },
+		"bad severity":    func(k *kind) { k.Severity = "fatal" },
+		"bad rank":        func(k *kind) { k.Rank = 11 },
+		"no public":       func(k *kind) { k.PublicMessage = "" },
+		"no number 0":     func(k *kind) { k.Number = 1 },
+		"bad from code":   func(k *kind) { k.FromGRPCCodes = []string{"Oops"} },
+		"bad from status": func(k *kind) { k.FromHTTPStatuses = []int{999} },
+	} {
+		k := valid()
+		change(&k)
+		require.Error(t, validate([]kind{k}), name)
+	}
+
+	other := valid()
+	other.Name = "other error"
+	require.Error(t, validate([]kind{valid(), other}), "same Go name and number")
+	other.GoName = "Other"
+	require.Error(t, validate([]kind{valid(), other}), "same number")
+	other.Number = 1
+	require.NoError(t, validate([]kind{valid(), other}))
+	first := valid()
+	first.FromGRPCCodes, first.FromHTTPStatuses = []string{"Unknown"}, []int{500}
+	other.FromGRPCCodes = []string{"Unknown"}
+	require.Error(t, validate([]kind{first, other}), "same from code")
+	other.FromGRPCCodes, other.FromHTTPStatuses = nil, []int{500}
+	require.Error(t, validate([]kind{first, other}), "same from status")
+	other.FromHTTPStatuses = []int{503}
+	require.NoError(t, validate([]kind{first, other}))
+	require.Error(t, validate([]kind{valid(), valid()}), "same name")
+}
@@ -0,0 +1,15 @@
+// Command khanlint checks the arguments of khanerr error constructors and
+// errors.Wrap. It can be run on its own, or by go vet:
+//
+//	go vet -vettool=$(which khanlint) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/StevenACoffman/khanerr/khanlint"
+)
+
+func main() {
+	singlechecker.Main(khanlint.Analyzer)
+}
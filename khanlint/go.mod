@@ -0,0 +1,13 @@
+module github.com/StevenACoffman/khanerr/khanlint
+
+// The other modules use go 1.21, like the core module, but the analyzer
+// keeps up with golang.org/x/tools, so that it understands code written for
+// new Go versions. x/tools v0.45.0 needs go 1.25.
+go 1.25.0
+
+require golang.org/x/tools v0.45.0
+
+require (
+	golang.org/x/mod v0.36.0 // indirect
+	golang.org/x/sync v0.20.0 // indirect
+)
@@ -0,0 +1,245 @@
+// Package khanlint is a go/analysis analyzer that checks the arguments
+// passed to khanerr's error constructors and to errors.Wrap.
+//
+// The constructors, like errors.NotFound and errors.Internal, take their
+// arguments as ...any, so mistakes are only reported at run time, by
+// prefixing the message with "Invalid error constructor argument(s)", and
+// errors.Wrap turns bad key/value arguments into an Internal error. This
+// analyzer reports them at compile time instead:
+//
+//   - constructor arguments of a type the constructors don't accept;
+//   - more than one message, error, Fields or context passed to a
+//     constructor without errors.KeepAll, or more than one Source,
+//     Severity or Public message;
+//   - errors.Wrap calls with an odd number of key/value arguments, or
+//     with keys that aren't constant strings;
+//   - calls to the standard library's errors.New, which khanerr code
+//     doesn't use.
+//
+// Run it with the khanlint command, on its own or as a vet tool:
+//
+//	go vet -vettool=$(which khanlint) ./...
+//
+// This package is a separate module so that the core khanerr module does
+// not depend on golang.org/x/tools.
+package khanlint
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// ErrorsPath is the import path of the khanerr errors package.
+const ErrorsPath = "github.com/StevenACoffman/khanerr/errors"
+
+// Analyzer checks calls to the khanerr error constructors and errors.Wrap,
+// and calls to the standard library's errors.New.
+var Analyzer = &analysis.Analyzer{
+	Name:     "khanlint",
+	Doc:      "check the arguments of khanerr error constructors and errors.Wrap",
+	URL:      "https://pkg.go.dev/github.com/StevenACoffman/khanerr/khanlint",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || fn.Pkg() == nil {
+			return
+		}
+		switch {
+		case fn.Pkg().Path() == "errors" && fn.Name() == "New":
+			pass.Reportf(call.Pos(),
+				"errors.New from the standard library is not allowed: "+
+					"use a khanerr constructor like errors.Internal")
+		case fn.Pkg().Path() != ErrorsPath:
+		case fn.Name() == "Wrap":
+			checkWrap(pass, call)
+		case isConstructor(fn):
+			checkConstructor(pass, call, fn)
+		}
+	})
+	return nil, nil
+}
+
+// isConstructor returns true if fn is an exported function or method of
+// the errors package with the signature of the constructors, i.e.
+// func(args ...any) error. That covers the constructors of the core kinds
+// and errorKind.New.
+func isConstructor(fn *types.Func) bool {
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || !fn.Exported() || !sig.Variadic() ||
+		sig.Params().Len() != 1 || sig.Results().Len() != 1 {
+		return false
+	}
+	elem := sig.Params().At(0).Type().(*types.Slice).Elem()
+	iface, ok := elem.Underlying().(*types.Interface)
+	return ok && iface.Empty() &&
+		types.Identical(sig.Results().At(0).Type(), errorType)
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+// argClass is what a constructor does with an argument, which depends on
+// its type.
+type argClass int
+
+const (
+	unknownArg argClass = iota // an interface, so it depends on the value
+	badArg
+	keepAllArg
+	errorArg
+	messageArg
+	sourceArg
+	severityArg
+	publicArg
+	fieldsArg
+	contextArg
+	fieldArg
+)
+
+// argNames are the names of the kinds of arguments that may be repeated
+// by mistake, for messages.
+var argNames = map[argClass]string{
+	errorArg:    "error",
+	messageArg:  "message",
+	sourceArg:   "Source",
+	severityArg: "Severity",
+	publicArg:   "Public message",
+	fieldsArg:   "Fields",
+	contextArg:  "context",
+}
+
+// classify returns what the constructors do with an argument of type t,
+// following the type switch in newError.
+func classify(pkg *types.Package, tv types.TypeAndValue) argClass {
+	t := tv.Type
+	switch {
+	case tv.IsNil():
+		return badArg
+	case isNamed(t, pkg, "keepAllArgs"):
+		return keepAllArg
+	case types.Implements(t, errorType.Underlying().(*types.Interface)):
+		return errorArg
+	case types.Identical(types.Default(t), types.Typ[types.String]):
+		return messageArg
+	case isNamed(t, pkg, "Source"):
+		return sourceArg
+	case isNamed(t, pkg, "Severity"):
+		return severityArg
+	case isNamed(t, pkg, "Public"):
+		return publicArg
+	case isNamed(t, pkg, "Fields"),
+		types.Identical(t, types.NewMap(types.Typ[types.String], types.NewInterfaceType(nil, nil))):
+		return fieldsArg
+	case implementsContext(pkg, t):
+		return contextArg
+	case isNamed(t, pkg, "Field"):
+		return fieldArg
+	case types.IsInterface(t):
+		return unknownArg
+	default:
+		return badArg
+	}
+}
+
+// isNamed returns true if t is the type called name in pkg.
+func isNamed(t types.Type, pkg *types.Package, name string) bool {
+	obj := pkg.Scope().Lookup(name)
+	return obj != nil && types.Identical(t, obj.Type())
+}
+
+// implementsContext returns true if t implements context.Context, which
+// the errors package imports.
+func implementsContext(pkg *types.Package, t types.Type) bool {
+	for _, imp := range pkg.Imports() {
+		if imp.Path() != "context" {
+			continue
+		}
+		ctx := imp.Scope().Lookup("Context")
+		return ctx != nil && types.Implements(t, ctx.Type().Underlying().(*types.Interface))
+	}
+	return false
+}
+
+// checkConstructor reports arguments to a constructor call that it would
+// report as invalid at run time.
+func checkConstructor(pass *analysis.Pass, call *ast.CallExpr, fn *types.Func) {
+	if call.Ellipsis.IsValid() {
+		// The arguments are only known at run time.
+		return
+	}
+	classes := make([]argClass, len(call.Args))
+	keepAll := false
+	for i, arg := range call.Args {
+		tv := pass.TypesInfo.Types[arg]
+		classes[i] = classify(fn.Pkg(), tv)
+		if classes[i] == keepAllArg && (tv.Value == nil || constant.BoolVal(tv.Value)) {
+			keepAll = true
+		}
+	}
+
+	name := types.ExprString(call.Fun)
+	seen := map[argClass]bool{}
+	for i, arg := range call.Args {
+		class := classes[i]
+		switch class {
+		case badArg:
+			pass.Reportf(arg.Pos(), "%s does not accept an argument of type %s",
+				name, typeString(pass, pass.TypesInfo.Types[arg].Type))
+		case sourceArg, severityArg, publicArg:
+			if seen[class] {
+				pass.Reportf(arg.Pos(), "%s is passed more than one %s", name, argNames[class])
+			}
+		case errorArg, messageArg, fieldsArg, contextArg:
+			if seen[class] && !keepAll {
+				pass.Reportf(arg.Pos(),
+					"%s is passed more than one %s: only the last is kept, unless errors.KeepAll is passed",
+					name, argNames[class])
+			}
+		}
+		seen[class] = true
+	}
+}
+
+// checkWrap reports errors.Wrap calls whose key/value arguments would make
+// it return an Internal error at run time.
+func checkWrap(pass *analysis.Pass, call *ast.CallExpr) {
+	if call.Ellipsis.IsValid() || len(call.Args) < 1 {
+		return
+	}
+	name := types.ExprString(call.Fun)
+	args := call.Args[1:]
+	if len(args)%2 != 0 {
+		pass.Reportf(call.Pos(), "%s is passed an odd number of key/value arguments", name)
+	}
+	for i := 0; i < len(args); i += 2 {
+		tv := pass.TypesInfo.Types[args[i]]
+		switch {
+		case !types.Identical(types.Default(tv.Type), types.Typ[types.String]):
+			pass.Reportf(args[i].Pos(), "%s key is not a string but %s", name, typeString(pass, tv.Type))
+		case tv.Value == nil:
+			pass.Reportf(args[i].Pos(), "%s key is not a constant", name)
+		}
+	}
+}
+
+// typeString returns t as it would be written in the package being
+// checked, give or take import names.
+func typeString(pass *analysis.Pass, t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if p == pass.Pkg {
+			return ""
+		}
+		return p.Name()
+	})
+}
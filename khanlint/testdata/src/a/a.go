@@ -0,0 +1,58 @@
+package a
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+type myString string
+
+func constructors(ctx context.Context, err error, other error, v any, kaid string) {
+	_ = errors.NotFound()
+	_ = errors.NotFound("No such user", err, errors.Fields{"kaid": kaid},
+		errors.Source("a.go"), ctx, errors.Field{Key: "k", Value: 1})
+	_ = errors.NotFound(map[string]any{"kaid": kaid}, errors.NotFoundKind)
+	_ = errors.NotFound("No user 123", errors.SeverityWarning, errors.Public("No such user"))
+	_ = errors.NotFound(v, fmt.Sprintf("No user %s", kaid))
+
+	_ = errors.NotFound(42)                        // want `errors.NotFound does not accept an argument of type int`
+	_ = errors.Internal(nil)                       // want `errors.Internal does not accept an argument of type untyped nil`
+	_ = errors.Internal(myString("oops"))          // want `errors.Internal does not accept an argument of type myString`
+	_ = errors.Internal([]string{"a"})             // want `errors.Internal does not accept an argument of type \[\]string`
+	_ = errors.Internal(map[string]string{})       // want `errors.Internal does not accept an argument of type map\[string\]string`
+	_ = errors.NotFoundKind.New(struct{ A int }{}) // want `errors.NotFoundKind.New does not accept an argument of type struct{A int}`
+
+	_ = errors.Internal("Unable to load", "oops")                    // want `errors.Internal is passed more than one message`
+	_ = errors.Internal(err, other)                                  // want `errors.Internal is passed more than one error`
+	_ = errors.Internal(errors.Fields{}, errors.Fields{})            // want `errors.Internal is passed more than one Fields`
+	_ = errors.Internal(ctx, ctx)                                    // want `errors.Internal is passed more than one context`
+	_ = errors.Internal(errors.Source("a"), errors.Source("b"))      // want `errors.Internal is passed more than one Source`
+	_ = errors.Internal(errors.SeverityInfo, errors.SeverityWarning) // want `errors.Internal is passed more than one Severity`
+	_ = errors.Internal(errors.Public("a"), errors.Public("b"))      // want `errors.Internal is passed more than one Public message`
+	_ = errors.Internal(errors.KeepAll, "Sync failed", "again", err, other, errors.Fields{}, errors.Fields{})
+	_ = errors.Internal(errors.KeepAll, errors.Source("a"), errors.Source("b")) // want `errors.Internal is passed more than one Source`
+
+	args := []any{"Unable to load", 42}
+	_ = errors.Internal(args...)
+}
+
+const attemptKey = "attempt"
+
+func wrap(err error, key string) {
+	_ = errors.Wrap(err)
+	_ = errors.Wrap(err, "kaid", "123", attemptKey, 2)
+	_ = errors.Wrap(err, "kaid")           // want `errors.Wrap is passed an odd number of key/value arguments`
+	_ = errors.Wrap(err, key, "123")       // want `errors.Wrap key is not a constant`
+	_ = errors.Wrap(err, 1, "123")         // want `errors.Wrap key is not a string but int`
+	_ = errors.Wrap(err, myString("k"), 1) // want `errors.Wrap key is not a string but myString`
+
+	kv := []any{"kaid", "123"}
+	_ = errors.Wrap(err, kv...)
+}
+
+func stdlib() error {
+	return stderrors.New("oops") // want `errors.New from the standard library is not allowed`
+}
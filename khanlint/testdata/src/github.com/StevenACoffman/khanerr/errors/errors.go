@@ -0,0 +1,48 @@
+// Package errors is a stand-in for the khanerr errors package, with the
+// declarations that khanlint looks at.
+package errors
+
+import "context"
+
+type errorKind string
+
+func (e errorKind) Error() string { return string(e) }
+
+func (e errorKind) New(args ...any) error { return e }
+
+const (
+	NotFoundKind = errorKind("not found")
+	InternalKind = errorKind("internal error")
+)
+
+type Fields map[string]any
+
+type Source string
+
+type Public string
+
+type Severity int
+
+const (
+	SeverityInfo Severity = iota + 2
+	SeverityWarning
+)
+
+type Field struct {
+	Key   string
+	Value any
+}
+
+type keepAllArgs bool
+
+const KeepAll keepAllArgs = true
+
+var _ context.Context
+
+func NotFound(args ...any) error { return NotFoundKind }
+
+func Internal(args ...any) error { return InternalKind }
+
+func Wrap(err error, args ...any) error { return err }
+
+func GetFields(err error) Fields { return nil }
@@ -0,0 +1,79 @@
+// Code generated by kindgen from kinds/kinds.yaml. DO NOT EDIT.
+
+/** The names of the core error kinds, as they are logged and sent over the wire. */
+export const ErrorKind = {
+    /**
+     * NotFound means that some requested resource wasn't found. If the
+     * resource couldn't be retrieved due to access control use
+     * UnauthorizedKind instead. If the resource couldn't be found because
+     * the input was invalid use InvalidInputKind instead.
+     */
+    NotFound: "not found",
+    /**
+     * InvalidInput means that there was a problem with the provided input.
+     * This kind indicates inputs that are problematic regardless of the state
+     * of the system. Use NotAllowedKind when the input is valid but
+     * conflicts with the state of the system.
+     */
+    InvalidInput: "invalid input error",
+    /**
+     * NotAllowed means that there was a problem due to the state of
+     * the system not matching the requested operation or input. For
+     * example, trying to create a username that is valid, but is already
+     * taken by another user. Use InvalidInputKind when the input isn't
+     * valid regardless of the state of the system. Use NotFoundKind when
+     * the failure is due to not being able to find a resource.
+     */
+    NotAllowed: "not allowed",
+    /**
+     * Unauthorized means that there was an access control problem.
+     */
+    Unauthorized: "unauthorized error",
+    /**
+     * Internal means that the function failed for a reason unrelated
+     * to its input or problems working with a remote system. Use this kind
+     * when other error kinds aren't appropriate.
+     */
+    Internal: "internal error",
+    /**
+     * NotImplemented means that the function isn't implemented.
+     */
+    NotImplemented: "not implemented error",
+    /**
+     * GraphqlResponse means that the graphql server returned an
+     * error code as part of the graphql response.  This kind of error
+     * is only ever returned by gqlclient calls.  It is set when the
+     * graphql call successfully executes, but the graphql response struct
+     * indicates the graphql request could not be executed due to an
+     * error.  (e.g. mutation.MyMutation.Error.Code == "UNAUTHORIZED")
+     */
+    GraphqlResponse: "graphql error response",
+    /**
+     * TransientKhanService means that there was a problem when contacting
+     * another Khan service that might be resolvable by retrying.
+     */
+    TransientKhanService: "transient khan service error",
+    /**
+     * KhanService means that there was a non-transient problem when
+     * contacting another Khan service.
+     */
+    KhanService: "khan service error",
+    /**
+     * TransientService means that there was a problem when making a
+     * request to a non-Khan service, e.g. datastore that might be
+     * resolvable by retrying.
+     */
+    TransientService: "transient service error",
+    /**
+     * Service means that there was a non-transient problem when making a
+     * request to a non-Khan service, e.g. datastore.
+     */
+    Service: "service error",
+    /**
+     * Unspecified means that no error kind was specified. Note that there
+     * isn't a constructor for this kind of error.
+     */
+    Unspecified: "unspecified error",
+} as const;
+
+export type ErrorKind = (typeof ErrorKind)[keyof typeof ErrorKind];
@@ -0,0 +1,23 @@
+module github.com/StevenACoffman/khanerr/otelerr
+
+go 1.21
+
+replace github.com/StevenACoffman/khanerr => ../
+
+require (
+	github.com/StevenACoffman/khanerr v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.12.1
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/sdk v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
+)
+
+require (
+	github.com/StevenACoffman/simplerr v0.0.0-20230419164504-91cf1c91bd28 // indirect
+	github.com/go-logr/logr v1.4.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/otel/metric v1.28.0 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+)
@@ -0,0 +1,181 @@
+// Package otelerr records khanerr errors on OpenTelemetry spans.
+//
+// span.RecordError only records the string from Error(). RecordError in
+// this package records the kind as the exception type, the error's message,
+// its fields as attributes and its stack trace, and sets the span status
+// from the kind:
+//
+//	otelerr.RecordError(span, err)
+//
+// WithTraceFields goes the other way, adding the trace and span IDs of the
+// current span to the fields of errors created with the context, so that
+// logged errors can be linked to their traces.
+//
+// This package is a separate module so that the core khanerr module does
+// not depend on OpenTelemetry.
+package otelerr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+// The fields that WithTraceFields adds to errors.
+const (
+	TraceIDKey = "traceID"
+	SpanIDKey  = "spanID"
+)
+
+// FieldAttributePrefix is put in front of the keys of the error's fields,
+// to make the attributes they are recorded as.
+const FieldAttributePrefix = "error.fields."
+
+// KindAttribute is the attribute that holds the error's kind, alongside
+// the standard exception attributes.
+const KindAttribute = attribute.Key("error.kind")
+
+// RecordError records err on span as an exception event, and sets the
+// span's status from the kind of err. It does nothing if err is nil.
+//
+// The event has the kind as its exception.type, the error's message as its
+// exception.message and its stack trace as its exception.stacktrace. Each
+// field of the error is an attribute named FieldAttributePrefix+key, with
+// an attribute type to match the field's value.
+//
+// The span status is set to Error for kinds served as 5xx by
+// errors.HTTPStatus, like InternalKind and the service kinds. Kinds that
+// are the client's fault, like NotFoundKind, leave the status alone, as
+// the OpenTelemetry conventions do for 4xx responses.
+func RecordError(span trace.Span, err error) {
+	if err == nil || !span.IsRecording() {
+		return
+	}
+
+	kind := errors.GetKind(err)
+	fields := errors.GetFields(err)
+	message, ok := fields[errors.MessageKey].(string)
+	if !ok {
+		message = err.Error()
+	}
+	delete(fields, errors.KindKey)
+	delete(fields, errors.MessageKey)
+
+	attrs := []attribute.KeyValue{
+		semconv.ExceptionType(kind.String()),
+		semconv.ExceptionMessage(message),
+		KindAttribute.String(kind.String()),
+	}
+	if stack := stackTrace(err); stack != "" {
+		attrs = append(attrs, semconv.ExceptionStacktrace(stack))
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		attrs = append(attrs, fieldAttribute(FieldAttributePrefix+k, fields[k]))
+	}
+	span.AddEvent(semconv.ExceptionEventName, trace.WithAttributes(attrs...))
+
+	if errors.HTTPStatus(err) >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, message)
+	}
+}
+
+// WithTraceFields returns a copy of ctx whose errors.WithFields fields
+// include the TraceIDKey and SpanIDKey of the span in ctx. Errors created
+// with the context then carry the IDs:
+//
+//	ctx = otelerr.WithTraceFields(ctx)
+//	...
+//	return errors.NotFound(ctx, "No such video")
+//
+// If ctx has no valid span, it is returned unchanged.
+func WithTraceFields(ctx context.Context) context.Context {
+	fields := TraceFields(ctx)
+	if len(fields) == 0 {
+		return ctx
+	}
+	return errors.WithFields(ctx, fields)
+}
+
+// TraceFields returns the trace and span IDs of the span in ctx, as
+// fields, or nil if ctx has no valid span.
+func TraceFields(ctx context.Context) errors.Fields {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return errors.Fields{
+		TraceIDKey: sc.TraceID().String(),
+		SpanIDKey:  sc.SpanID().String(),
+	}
+}
+
+// fieldAttribute turns a field into an attribute of the matching type.
+// Values with no matching type are recorded using errors.StringifyField.
+func fieldAttribute(key string, value any) attribute.KeyValue {
+	k := attribute.Key(key)
+	switch v := value.(type) {
+	case string:
+		return k.String(v)
+	case bool:
+		return k.Bool(v)
+	case int:
+		return k.Int(v)
+	case int8:
+		return k.Int64(int64(v))
+	case int16:
+		return k.Int64(int64(v))
+	case int32:
+		return k.Int64(int64(v))
+	case int64:
+		return k.Int64(v)
+	case uint8:
+		return k.Int64(int64(v))
+	case uint16:
+		return k.Int64(int64(v))
+	case uint32:
+		return k.Int64(int64(v))
+	case float32:
+		return k.Float64(float64(v))
+	case float64:
+		return k.Float64(v)
+	case []string:
+		return k.StringSlice(v)
+	case []bool:
+		return k.BoolSlice(v)
+	case []int:
+		return k.IntSlice(v)
+	case []int64:
+		return k.Int64Slice(v)
+	case []float64:
+		return k.Float64Slice(v)
+	case error:
+		return k.String(v.Error())
+	case fmt.Stringer:
+		return k.String(v.String())
+	default:
+		return k.String(errors.StringifyField(v))
+	}
+}
+
+// stackTrace formats the stack trace of err like a Go panic does.
+func stackTrace(err error) string {
+	var b strings.Builder
+	for _, frame := range errors.GetStackTrace(err) {
+		_, _ = fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+	return b.String()
+}
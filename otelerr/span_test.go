@@ -0,0 +1,120 @@
+package otelerr_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/StevenACoffman/khanerr/errors"
+	"github.com/StevenACoffman/khanerr/otelerr"
+)
+
+type otelSuite struct {
+	suite.Suite
+	exporter *tracetest.InMemoryExporter
+	provider *sdktrace.TracerProvider
+}
+
+func (ots *otelSuite) SetupTest() {
+	ots.exporter = tracetest.NewInMemoryExporter()
+	ots.provider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(ots.exporter))
+}
+
+// record records err on a new span and returns the exported span.
+func (ots *otelSuite) record(err error) tracetest.SpanStub {
+	_, span := ots.provider.Tracer("test").Start(context.Background(), "op")
+	otelerr.RecordError(span, err)
+	span.End()
+	spans := ots.exporter.GetSpans()
+	ots.Require().Len(spans, 1)
+	return spans[0]
+}
+
+// attrs turns the attributes of an event into a map, for comparison.
+func attrs(kvs []attribute.KeyValue) map[string]any {
+	m := map[string]any{}
+	for _, kv := range kvs {
+		m[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	return m
+}
+
+func (ots *otelSuite) TestRecordError() {
+	inner := errors.NotFound("No such user", errors.Fields{"kaid": "123"})
+	e := errors.Internal("Unable to load", inner, errors.Fields{
+		"attempt": 2,
+		"ratio":   0.5,
+		"retried": true,
+		"tags":    []string{"a", "b"},
+		"cause":   fmt.Errorf("boom"),
+		"odd":     struct{ A int }{1},
+	})
+
+	span := ots.record(e)
+	ots.Require().Equal(codes.Error, span.Status.Code)
+	ots.Require().Equal("Unable to load", span.Status.Description)
+	ots.Require().Len(span.Events, 1)
+	event := span.Events[0]
+	ots.Require().Equal("exception", event.Name)
+
+	recorded := attrs(event.Attributes)
+	stack, ok := recorded["exception.stacktrace"].(string)
+	ots.Require().True(ok)
+	ots.Require().Contains(stack, "otelerr_test.(*otelSuite).TestRecordError")
+	delete(recorded, "exception.stacktrace")
+	ots.Require().Equal(map[string]any{
+		"exception.type":       "internal error",
+		"exception.message":    "Unable to load",
+		"error.kind":           "internal error",
+		"error.fields.attempt": int64(2),
+		"error.fields.ratio":   0.5,
+		"error.fields.retried": true,
+		"error.fields.tags":    []string{"a", "b"},
+		"error.fields.cause":   "boom",
+		"error.fields.odd":     "{1}",
+		"error.fields.kaid":    "123",
+	}, recorded)
+}
+
+func (ots *otelSuite) TestRecordErrorStatus() {
+	// Errors that are the client's fault don't mark the span as failed.
+	span := ots.record(errors.NotFound("No such user"))
+	ots.Require().Equal(codes.Unset, span.Status.Code)
+	ots.Require().Len(span.Events, 1)
+	ots.exporter.Reset()
+
+	span = ots.record(fmt.Errorf("plain"))
+	ots.Require().Equal(codes.Error, span.Status.Code)
+	ots.Require().Equal("unspecified error", attrs(span.Events[0].Attributes)["exception.type"])
+	ots.Require().Equal("plain", attrs(span.Events[0].Attributes)["exception.message"])
+	ots.exporter.Reset()
+
+	span = ots.record(nil)
+	ots.Require().Equal(codes.Unset, span.Status.Code)
+	ots.Require().Empty(span.Events)
+}
+
+func (ots *otelSuite) TestWithTraceFields() {
+	ctx, span := ots.provider.Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+
+	e := errors.NotFound(otelerr.WithTraceFields(ctx), "No such video")
+	fields := errors.GetFields(e)
+	ots.Require().Equal(span.SpanContext().TraceID().String(), fields[otelerr.TraceIDKey])
+	ots.Require().Equal(span.SpanContext().SpanID().String(), fields[otelerr.SpanIDKey])
+
+	// Without a span, there is nothing to add.
+	ctx = context.Background()
+	ots.Require().Equal(ctx, otelerr.WithTraceFields(ctx))
+	ots.Require().Nil(otelerr.TraceFields(ctx))
+}
+
+func TestOtel(t *testing.T) {
+	suite.Run(t, new(otelSuite))
+}
@@ -0,0 +1,236 @@
+// Package sentryerr reports khanerr errors to Sentry.
+//
+// sentry.CaptureException only sees an error's Go type and Error(), so
+// every khan error looks the same to Sentry. NewEvent builds the event
+// from the error instead: each error in the chain becomes an exception
+// whose type is its kind, the error's fields become tags and context, and
+// the event is grouped by kind and errors.Fingerprint:
+//
+//	exporter := sentryerr.NewExporter(sentryerr.HubTransport{}, nil)
+//	_ = exporter.Export(ctx, err)
+//
+// Events are sent through a Transport. HubTransport sends them with the
+// Sentry SDK, and MemoryTransport keeps them, for tests.
+//
+// This package is a separate module so that the core khanerr module does
+// not depend on the Sentry SDK.
+package sentryerr
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+// KindTag is the tag that holds the error's kind.
+const KindTag = "kind"
+
+// FieldsContext is the event context that holds the fields that aren't
+// tags.
+const FieldsContext = "fields"
+
+// The mechanism types and source that the Sentry SDK gives the exceptions
+// of wrapped errors. Older versions of the SDK don't export them.
+const (
+	mechanismTypeGeneric = "generic"
+	mechanismTypeChained = "chained"
+	mechanismSourceCause = "cause"
+)
+
+// Options controls how NewEvent builds events.
+type Options struct {
+	// TagKeys lists the fields that become tags, which Sentry can search
+	// and aggregate. They should have few distinct values. The other
+	// fields go into the FieldsContext context.
+	TagKeys []string
+
+	// Fingerprint controls the errors.Fingerprint used to group events.
+	Fingerprint *errors.FingerprintOptions
+}
+
+func (o *Options) isTag(key string) bool {
+	return o != nil && slices.Contains(o.TagKeys, key)
+}
+
+func (o *Options) fingerprint() *errors.FingerprintOptions {
+	if o == nil {
+		return nil
+	}
+	return o.Fingerprint
+}
+
+// Level returns the Sentry level for the severity of err, from
+// errors.GetSeverity.
+func Level(err error) sentry.Level {
+	switch errors.GetSeverity(err) {
+	case errors.SeverityDebug:
+		return sentry.LevelDebug
+	case errors.SeverityInfo:
+		return sentry.LevelInfo
+	case errors.SeverityWarning:
+		return sentry.LevelWarning
+	case errors.SeverityCritical:
+		return sentry.LevelFatal
+	}
+	return sentry.LevelError
+}
+
+// NewEvent returns a Sentry event for err, or nil if err is nil.
+//
+// The event has an exception for each error in the chain, innermost first
+// as Sentry expects. The exception of a khan error has its kind as the
+// type, its message as the value and the stack frames it added as its
+// stack trace; other errors have their Go type and Error(). The fields of
+// err, redacted as by errors.GetFields, are tags if they are in
+// opts.TagKeys and are in the FieldsContext context otherwise. The kind
+// is also the KindTag tag. The event's level is from errors.GetSeverity.
+//
+// Events are grouped by the kind and errors.Fingerprint of err, so that
+// errors of the same kind from the same code path are one issue.
+func NewEvent(err error, opts *Options) *sentry.Event {
+	if err == nil {
+		return nil
+	}
+	kind := errors.GetKind(err).String()
+
+	event := sentry.NewEvent()
+	event.Level = Level(err)
+	event.Timestamp = time.Now()
+	event.Exception = exceptions(err)
+	event.Fingerprint = []string{kind, errors.FingerprintWithOptions(err, opts.fingerprint())}
+	event.Tags[KindTag] = kind
+
+	extra := sentry.Context{}
+	for k, v := range errors.GetFields(err) {
+		switch {
+		case k == errors.KindKey || k == errors.MessageKey:
+			continue
+		case opts.isTag(k):
+			event.Tags[k] = errors.StringifyField(v)
+		default:
+			extra[k] = v
+		}
+	}
+	if len(extra) > 0 {
+		event.Contexts[FieldsContext] = extra
+	}
+	return event
+}
+
+// exceptions returns an exception for each error in the chain of err,
+// innermost first.
+func exceptions(err error) []sentry.Exception {
+	var errs []error
+	for c := err; c != nil; c = errors.Unwrap(c) {
+		errs = append(errs, c)
+	}
+	slices.Reverse(errs)
+	chain := make([]sentry.Exception, len(errs))
+	var innerFrames []runtime.Frame
+	for i, c := range errs {
+		chain[i] = exception(c, innerFrames)
+		innerFrames = nil
+		if khanErr, ok := c.(*errors.Error); ok {
+			innerFrames = khanErr.StackTrace()
+		}
+	}
+	if len(chain) > 1 {
+		// Link the exceptions the way the Sentry SDK does for wrapped
+		// errors, so that Sentry shows them as one chain.
+		for i := range chain {
+			mechanism := &sentry.Mechanism{
+				Type:        mechanismTypeChained,
+				ExceptionID: len(chain) - 1 - i,
+			}
+			if i < len(chain)-1 {
+				parentID := mechanism.ExceptionID - 1
+				mechanism.ParentID = &parentID
+				mechanism.Source = mechanismSourceCause
+			} else {
+				mechanism.Type = mechanismTypeGeneric
+			}
+			chain[i].Mechanism = mechanism
+		}
+	}
+	return chain
+}
+
+// exception returns the exception for err alone, not the errors it wraps.
+// innerFrames are the stack frames of the error that err wraps, if any.
+func exception(err error, innerFrames []runtime.Frame) sentry.Exception {
+	khanErr, isKhanErr := err.(*errors.Error)
+	switch {
+	case isKhanErr:
+		return sentry.Exception{
+			Type:       khanErr.Kind().String(),
+			Value:      khanErr.Message(),
+			Stacktrace: stacktrace(khanErr, innerFrames),
+		}
+	case errors.IsKhanError(err):
+		// An error from errors.Join or Combine.
+		message, _ := errors.GetFields(err)[errors.MessageKey].(string)
+		return sentry.Exception{
+			Type:  errors.GetKind(err).String(),
+			Value: message,
+		}
+	default:
+		return sentry.Exception{
+			Type:  fmt.Sprintf("%T", err),
+			Value: err.Error(),
+		}
+	}
+}
+
+// stacktrace returns the stack frames that e added to innerFrames, those
+// of the error it wraps, oldest call first as Sentry expects, or nil if
+// there are none.
+func stacktrace(e *errors.Error, innerFrames []runtime.Frame) *sentry.Stacktrace {
+	frames := elideSharedFrames(innerFrames, e.StackTrace())
+	if len(frames) == 0 {
+		return nil
+	}
+	st := &sentry.Stacktrace{Frames: make([]sentry.Frame, len(frames))}
+	for i, frame := range frames {
+		st.Frames[len(frames)-1-i] = sentry.NewFrame(frame)
+	}
+	return st
+}
+
+// elideSharedFrames returns frames without the outermost calls that it
+// has in common with inner, like the %+v format of khan errors does. At
+// least one frame is always kept.
+func elideSharedFrames(inner, frames []runtime.Frame) []runtime.Frame {
+	i, j := len(inner)-1, len(frames)-1
+	for i >= 0 && j > 0 && inner[i].PC == frames[j].PC {
+		i--
+		j--
+	}
+	return frames[:j+1]
+}
+
+// Exporter turns errors into events and sends them through a Transport.
+type Exporter struct {
+	transport Transport
+	opts      *Options
+}
+
+// NewExporter returns an Exporter that builds events with opts, which may
+// be nil, and sends them through transport.
+func NewExporter(transport Transport, opts *Options) *Exporter {
+	return &Exporter{transport: transport, opts: opts}
+}
+
+// Export sends the event for err through the exporter's transport, and
+// returns the transport's error. It does nothing if err is nil.
+func (x *Exporter) Export(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	return x.transport.SendEvent(ctx, NewEvent(err, x.opts))
+}
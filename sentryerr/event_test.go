@@ -0,0 +1,159 @@
+package sentryerr_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/StevenACoffman/khanerr/errors"
+	"github.com/StevenACoffman/khanerr/sentryerr"
+)
+
+type sentrySuite struct {
+	suite.Suite
+	transport *sentryerr.MemoryTransport
+	exporter  *sentryerr.Exporter
+}
+
+func (ss *sentrySuite) SetupTest() {
+	ss.transport = &sentryerr.MemoryTransport{}
+	ss.exporter = sentryerr.NewExporter(ss.transport,
+		&sentryerr.Options{TagKeys: []string{"service"}})
+}
+
+// export exports err and returns the event that was sent.
+func (ss *sentrySuite) export(err error) *sentry.Event {
+	ss.Require().NoError(ss.exporter.Export(context.Background(), err))
+	events := ss.transport.Events()
+	ss.Require().Len(events, 1)
+	ss.transport.Reset()
+	return events[0]
+}
+
+func loadUser(kaid string) error {
+	return errors.NotFound("No such user", errors.Fields{"kaid": kaid})
+}
+
+func (ss *sentrySuite) TestExport() {
+	e := errors.Internal("Unable to load", loadUser("123"),
+		errors.Fields{"service": "users", "attempt": 2})
+
+	event := ss.export(e)
+	ss.Require().Equal(sentry.LevelFatal, event.Level)
+	ss.Require().Equal(map[string]string{"kind": "internal error", "service": "users"}, event.Tags)
+	ss.Require().Equal(sentry.Context{"kaid": "123", "attempt": 2},
+		event.Contexts[sentryerr.FieldsContext])
+
+	ss.Require().Len(event.Exception, 2)
+	inner, outer := event.Exception[0], event.Exception[1]
+	ss.Require().Equal("not found", inner.Type)
+	ss.Require().Equal("No such user", inner.Value)
+	ss.Require().Equal("internal error", outer.Type)
+	ss.Require().Equal("Unable to load", outer.Value)
+	ss.Require().Equal(1, inner.Mechanism.ExceptionID)
+	ss.Require().Equal(0, *inner.Mechanism.ParentID)
+	ss.Require().Equal(0, outer.Mechanism.ExceptionID)
+	ss.Require().Nil(outer.Mechanism.ParentID)
+
+	// The innermost frame, where the error was made, is last.
+	frames := inner.Stacktrace.Frames
+	ss.Require().Equal("loadUser", frames[len(frames)-1].Function)
+	ss.Require().Equal("github.com/StevenACoffman/khanerr/sentryerr_test",
+		frames[len(frames)-1].Module)
+	ss.Require().NotNil(outer.Stacktrace)
+}
+
+func loadProfile(kaid string) error {
+	return errors.Internal("Unable to load profile", loadUser(kaid))
+}
+
+func (ss *sentrySuite) TestWrappedStacktrace() {
+	event := ss.export(loadProfile("123"))
+	ss.Require().Len(event.Exception, 2)
+	inner, outer := event.Exception[0], event.Exception[1]
+
+	// The outer error only has the frames that the inner one doesn't.
+	ss.Require().Len(outer.Stacktrace.Frames, 1)
+	ss.Require().Equal("loadProfile", outer.Stacktrace.Frames[0].Function)
+	frames := inner.Stacktrace.Frames
+	ss.Require().Greater(len(frames), 2)
+	ss.Require().Equal("loadUser", frames[len(frames)-1].Function)
+	ss.Require().Equal("loadProfile", frames[len(frames)-2].Function)
+}
+
+func (ss *sentrySuite) TestNonKhanCause() {
+	event := ss.export(errors.Service(fmt.Errorf("connection reset")))
+	ss.Require().Len(event.Exception, 2)
+	ss.Require().Equal("*errors.errorString", event.Exception[0].Type)
+	ss.Require().Equal("connection reset", event.Exception[0].Value)
+	ss.Require().Equal("service error", event.Exception[1].Type)
+
+	// An error on its own isn't a chain.
+	event = ss.export(loadUser("123"))
+	ss.Require().Len(event.Exception, 1)
+	ss.Require().Nil(event.Exception[0].Mechanism)
+}
+
+func (ss *sentrySuite) TestJoin() {
+	event := ss.export(errors.Join(loadUser("1"), errors.Internal()))
+	ss.Require().Len(event.Exception, 1)
+	ss.Require().Equal("internal error", event.Exception[0].Type)
+	ss.Require().Equal("2 errors occurred", event.Exception[0].Value)
+}
+
+func (ss *sentrySuite) TestFingerprint() {
+	first := ss.export(loadUser("123"))
+	second := ss.export(loadUser("456"))
+	ss.Require().Equal(first.Fingerprint, second.Fingerprint)
+	ss.Require().Equal("not found", first.Fingerprint[0])
+
+	other := ss.export(errors.NotFound("No such user", errors.Fields{"kaid": "123"}))
+	ss.Require().NotEqual(first.Fingerprint, other.Fingerprint)
+}
+
+func (ss *sentrySuite) TestLevel() {
+	ss.Require().Equal(sentry.LevelInfo, ss.export(loadUser("123")).Level)
+	ss.Require().Equal(sentry.LevelWarning,
+		ss.export(errors.Internal(errors.SeverityWarning)).Level)
+	ss.Require().Equal(sentry.LevelError, ss.export(fmt.Errorf("plain")).Level)
+}
+
+func (ss *sentrySuite) TestRedaction() {
+	event := ss.export(errors.Unauthorized(errors.Fields{"token": errors.Secret("t0k3n")}))
+	ss.Require().Equal(errors.RedactedValue, event.Contexts[sentryerr.FieldsContext]["token"])
+}
+
+func (ss *sentrySuite) TestNil() {
+	ss.Require().NoError(ss.exporter.Export(context.Background(), nil))
+	ss.Require().Empty(ss.transport.Events())
+	ss.Require().Nil(sentryerr.NewEvent(nil, nil))
+}
+
+func (ss *sentrySuite) TestHubTransport() {
+	mock := &sentry.MockTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Transport: mock})
+	ss.Require().NoError(err)
+	hub := sentry.NewHub(client, sentry.NewScope())
+	hub.Scope().SetTag("release", "v1")
+
+	exporter := sentryerr.NewExporter(sentryerr.HubTransport{Hub: hub}, nil)
+	ss.Require().NoError(exporter.Export(context.Background(), loadUser("123")))
+	events := mock.Events()
+	ss.Require().Len(events, 1)
+	ss.Require().Equal("not found", events[0].Tags[sentryerr.KindTag])
+	ss.Require().Equal("v1", events[0].Tags["release"])
+
+	noClient := sentry.NewHub(nil, sentry.NewScope())
+	err = sentryerr.HubTransport{Hub: noClient}.SendEvent(
+		context.Background(), sentryerr.NewEvent(loadUser("123"), nil))
+	ss.Require().True(errors.Is(err, errors.InternalKind))
+	ss.Require().True(strings.Contains(err.Error(), "no client"))
+}
+
+func TestSentry(t *testing.T) {
+	suite.Run(t, new(sentrySuite))
+}
@@ -0,0 +1,18 @@
+module github.com/StevenACoffman/khanerr/sentryerr
+
+go 1.21
+
+replace github.com/StevenACoffman/khanerr => ../
+
+require (
+	github.com/StevenACoffman/khanerr v0.0.0-00010101000000-000000000000
+	github.com/getsentry/sentry-go v0.35.1
+	github.com/stretchr/testify v1.12.1
+)
+
+require (
+	github.com/StevenACoffman/simplerr v0.0.0-20230419164504-91cf1c91bd28 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+)
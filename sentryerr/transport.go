@@ -0,0 +1,74 @@
+package sentryerr
+
+import (
+	"context"
+	"sync"
+
+	"github.com/getsentry/sentry-go"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+// Transport sends events to Sentry, or wherever else they should go.
+type Transport interface {
+	SendEvent(ctx context.Context, event *sentry.Event) error
+}
+
+// HubTransport sends events with a Sentry hub, which adds its scope to
+// them, like the tags and user set with sentry.ConfigureScope, and sends
+// them with the client that sentry.Init set up.
+type HubTransport struct {
+	// Hub is the hub to send events with. If it is nil, the hub of the
+	// context is used, as set by the Sentry HTTP middleware, or else
+	// sentry.CurrentHub().
+	Hub *sentry.Hub
+}
+
+// SendEvent sends event with the hub. It returns an Internal error if the
+// hub has no client, e.g. because sentry.Init hasn't been called. Events
+// that the client drops, e.g. because of sampling, are not errors.
+func (t HubTransport) SendEvent(ctx context.Context, event *sentry.Event) error {
+	hub := t.Hub
+	if hub == nil {
+		hub = sentry.GetHubFromContext(ctx)
+	}
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+	if hub.Client() == nil {
+		return errors.Internal("Sentry hub has no client")
+	}
+	hub.CaptureEvent(event)
+	return nil
+}
+
+// MemoryTransport keeps the events sent through it, so that tests can
+// check them without a Sentry server. It is safe for concurrent use.
+type MemoryTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+// SendEvent keeps event. It never fails.
+func (t *MemoryTransport) SendEvent(_ context.Context, event *sentry.Event) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+	return nil
+}
+
+// Events returns the events sent so far, oldest first.
+func (t *MemoryTransport) Events() []*sentry.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events := make([]*sentry.Event, len(t.events))
+	copy(events, t.events)
+	return events
+}
+
+// Reset forgets the events sent so far.
+func (t *MemoryTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = nil
+}
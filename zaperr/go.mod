@@ -0,0 +1,17 @@
+module github.com/StevenACoffman/khanerr/zaperr
+
+go 1.21
+
+replace github.com/StevenACoffman/khanerr => ../
+
+require (
+	github.com/StevenACoffman/khanerr v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.12.1
+	go.uber.org/zap v1.28.0
+)
+
+require (
+	github.com/StevenACoffman/simplerr v0.0.0-20230419164504-91cf1c91bd28 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+)
@@ -0,0 +1,149 @@
+// Package zaperr logs khanerr errors with go.uber.org/zap.
+//
+// zap.Error only logs the string from Error(). The fields from this
+// package log a khan error as an object instead, with its kind, message,
+// fields, wrapped causes and stack trace, so that they can be queried in
+// structured logs:
+//
+//	logger.Error("Unable to load user", zaperr.Error(err))
+//
+// Log does the same at the level for the error's severity:
+//
+//	zaperr.Log(logger, "Unable to load user", err)
+//
+// This package is a separate module so that the core khanerr module does
+// not depend on zap.
+package zaperr
+
+import (
+	"runtime"
+	"sort"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/StevenACoffman/khanerr/errors"
+)
+
+// Error returns a field that logs err under the "error" key, like
+// zap.Error does. A nil err is skipped.
+func Error(err error) zap.Field {
+	return NamedError("error", err)
+}
+
+// NamedError is like Error, but logs err under key.
+func NamedError(key string, err error) zap.Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	return zap.Object(key, Marshaler(err))
+}
+
+// Level returns the zap level for the severity of err, from
+// errors.GetSeverity. zap has no level between error and the ones that
+// panic or exit, so errors.SeverityCritical is zapcore.ErrorLevel too.
+func Level(err error) zapcore.Level {
+	switch errors.GetSeverity(err) {
+	case errors.SeverityDebug:
+		return zapcore.DebugLevel
+	case errors.SeverityInfo:
+		return zapcore.InfoLevel
+	case errors.SeverityWarning:
+		return zapcore.WarnLevel
+	}
+	return zapcore.ErrorLevel
+}
+
+// Log logs msg and err, along with fields, at Level(err).
+func Log(logger *zap.Logger, msg string, err error, fields ...zap.Field) {
+	if ce := logger.Check(Level(err), msg); ce != nil {
+		ce.Write(append(fields, Error(err))...)
+	}
+}
+
+// Marshaler returns an object marshaler for err, for use with
+// zap.Object or zapcore.ObjectEncoder.AddObject. err may be any error;
+// errors that aren't khan errors have an unspecified kind and their
+// Error() as the message.
+func Marshaler(err error) zapcore.ObjectMarshaler {
+	return errorMarshaler{err: err}
+}
+
+type errorMarshaler struct {
+	err error
+}
+
+// MarshalLogObject writes the kind, message, fields, causes and stack
+// trace of the error.
+func (m errorMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	fields := errors.GetFields(m.err)
+	enc.AddString("kind", errors.GetKind(m.err).String())
+	message, ok := fields[errors.MessageKey].(string)
+	if !ok {
+		message = m.err.Error()
+	}
+	enc.AddString("message", message)
+
+	delete(fields, errors.KindKey)
+	delete(fields, errors.MessageKey)
+	if len(fields) > 0 {
+		if err := enc.AddObject("fields", fieldsMarshaler(fields)); err != nil {
+			return err
+		}
+	}
+	if causes := errors.GetWrappedErrors(m.err); len(causes) > 0 {
+		if err := enc.AddArray("causes", causesMarshaler(causes)); err != nil {
+			return err
+		}
+	}
+	if stack := stackMarshaler(errors.GetStackTrace(m.err)); len(stack) > 0 {
+		if err := enc.AddArray("stacktrace", stack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldsMarshaler writes each field with the encoder method for its type.
+type fieldsMarshaler errors.Fields
+
+func (f fieldsMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		zap.Any(k, f[k]).AddTo(enc)
+	}
+	return nil
+}
+
+type causesMarshaler []errors.Fields
+
+func (c causesMarshaler) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, cause := range c {
+		if err := enc.AppendObject(fieldsMarshaler(cause)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type stackMarshaler []runtime.Frame
+
+func (s stackMarshaler) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, frame := range s {
+		err := enc.AppendObject(zapcore.ObjectMarshalerFunc(
+			func(enc zapcore.ObjectEncoder) error {
+				enc.AddString("function", frame.Function)
+				enc.AddString("file", frame.File)
+				enc.AddInt("line", frame.Line)
+				return nil
+			}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
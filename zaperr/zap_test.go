@@ -0,0 +1,92 @@
+package zaperr_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/StevenACoffman/khanerr/errors"
+	"github.com/StevenACoffman/khanerr/zaperr"
+)
+
+type zapSuite struct{ suite.Suite }
+
+// logJSON logs field with a JSON logger and returns the decoded entry.
+func (zs *zapSuite) logJSON(field zap.Field) map[string]any {
+	var buf bytes.Buffer
+	core := zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		zapcore.AddSync(&buf),
+		zapcore.DebugLevel)
+	zap.New(core).Error("failed", field)
+
+	var entry map[string]any
+	zs.Require().NoError(json.Unmarshal(buf.Bytes(), &entry))
+	return entry
+}
+
+func (zs *zapSuite) TestError() {
+	inner := errors.NotFound("No such user", errors.Fields{"kaid": "123"})
+	e := errors.Internal("Unable to load", inner, errors.Fields{"attempt": 2})
+
+	logged, ok := zs.logJSON(zaperr.Error(e))["error"].(map[string]any)
+	zs.Require().True(ok)
+	zs.Require().Equal("internal error", logged["kind"])
+	zs.Require().Equal("Unable to load", logged["message"])
+	zs.Require().Equal(map[string]any{"attempt": 2.0, "kaid": "123"}, logged["fields"])
+	zs.Require().Equal([]any{map[string]any{
+		"Kind": "not found", "Message": "No such user", "kaid": "123",
+	}}, logged["causes"])
+
+	stack, ok := logged["stacktrace"].([]any)
+	zs.Require().True(ok)
+	zs.Require().NotEmpty(stack)
+	frame, ok := stack[0].(map[string]any)
+	zs.Require().True(ok)
+	zs.Require().Equal("github.com/StevenACoffman/khanerr/zaperr_test.(*zapSuite).TestError",
+		frame["function"])
+	zs.Require().Contains(frame["file"], "zap_test.go")
+}
+
+func (zs *zapSuite) TestNamedError() {
+	entry := zs.logJSON(zaperr.NamedError("cause", fmt.Errorf("plain")))
+	zs.Require().Equal(
+		map[string]any{"kind": "unspecified error", "message": "plain"},
+		entry["cause"])
+
+	entry = zs.logJSON(zaperr.Error(nil))
+	zs.Require().NotContains(entry, "error")
+}
+
+func (zs *zapSuite) TestLog() {
+	zs.Require().Equal(zapcore.InfoLevel, zaperr.Level(errors.NotFound()))
+	zs.Require().Equal(zapcore.WarnLevel, zaperr.Level(errors.Internal(errors.SeverityWarning)))
+	zs.Require().Equal(zapcore.ErrorLevel, zaperr.Level(errors.Internal()))
+
+	var buf bytes.Buffer
+	core := zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		zapcore.AddSync(&buf),
+		zapcore.InfoLevel)
+	logger := zap.New(core)
+
+	zaperr.Log(logger, "failed", errors.NotFound("No such user"), zap.Int("attempt", 2))
+	var entry map[string]any
+	zs.Require().NoError(json.Unmarshal(buf.Bytes(), &entry))
+	zs.Require().Equal("info", entry["level"])
+	zs.Require().Equal(2.0, entry["attempt"])
+	zs.Require().Contains(entry, "error")
+
+	buf.Reset()
+	zaperr.Log(logger, "failed", errors.NotFound(errors.SeverityDebug))
+	zs.Require().Empty(buf.String())
+}
+
+func TestZap(t *testing.T) {
+	suite.Run(t, new(zapSuite))
+}